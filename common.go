@@ -3,6 +3,7 @@ package common
 import (
 	"github.com/EvisuXiao/andrews-common/config"
 	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/trace"
 	"github.com/EvisuXiao/andrews-common/pkg/validator"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
@@ -10,6 +11,14 @@ import (
 func Init(serviceName string, cfgs ...config.IConfig) {
 	validator.Init()
 	config.Init(serviceName, cfgs...)
+	logging.Init()
+	cfg := config.GetServerConfig().Trace
+	trace.Init(&trace.Option{
+		Exporter:   cfg.Exporter,
+		Endpoint:   cfg.Endpoint,
+		SampleRate: cfg.SampleRate,
+		Service:    config.GetServiceName(),
+	})
 }
 
 func Stop() {
@@ -17,4 +26,6 @@ func Stop() {
 	if utils.HasErr(err) {
 		logging.Error("stop process err: %+v", err)
 	}
+	trace.Shutdown()
+	_ = logging.Close()
 }
@@ -1,9 +1,5 @@
 package config
 
-import (
-	"time"
-)
-
 type Caches struct {
 	Redis map[string]*Redis
 }
@@ -38,8 +34,4 @@ func (c *Caches) FileType() string {
 }
 
 func (c *Caches) Init() {
-	for _, cache := range CacheConfigs.Redis {
-		cache.Timeout.Read = cache.Timeout.Read * time.Second
-		cache.Timeout.Write = cache.Timeout.Write * time.Second
-	}
 }
@@ -1,16 +1,25 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"sort"
+	"sync"
 
 	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/pkg/apollo"
+	"github.com/EvisuXiao/andrews-common/pkg/consul"
+	"github.com/EvisuXiao/andrews-common/pkg/etcd"
 	"github.com/EvisuXiao/andrews-common/pkg/nacos"
+	"github.com/EvisuXiao/andrews-common/pkg/validator"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
 const (
 	CenterNacos  = "nacos"
 	CenterApollo = "apollo"
+	CenterEtcd   = "etcd"
+	CenterConsul = "consul"
 )
 
 type ICenter interface {
@@ -20,15 +29,56 @@ type ICenter interface {
 	CancelListenConfig(string) error
 }
 
+// IFileTypeCenter 配置中心按文件类型区分配置时实现, 读取前传入配置实际使用的文件类型
+type IFileTypeCenter interface {
+	SetFileType(dataId, fileType string)
+}
+
+// CenterAdapter 根据中心配置初始化配置中心客户端, 配置无效或初始化失败时返回错误
+type CenterAdapter func(*Center) (ICenter, error)
+
+// Center 各适配器配置仅在选中时校验
 type Center struct {
-	Nacos *constants.Nacos `json:"nacos"`
+	Nacos  *constants.Nacos  `json:"nacos" binding:"-"`
+	Apollo *constants.Apollo `json:"apollo" binding:"-"`
+	Etcd   *constants.Etcd   `json:"etcd" binding:"-"`
+	Consul *constants.Consul `json:"consul" binding:"-"`
 }
 
 var (
-	centerClient ICenter
-	centerConfig = &Center{}
+	centerClient   ICenter
+	centerConfig   = &Center{}
+	centerLoaded   bool
+	listening      []string
+	centerAdapters = make(map[string]CenterAdapter)
 )
 
+func init() {
+	RegisterCenterAdapter(CenterNacos, initNacosCenter)
+	RegisterCenterAdapter(CenterApollo, initApolloCenter)
+	RegisterCenterAdapter(CenterEtcd, initEtcdCenter)
+	RegisterCenterAdapter(CenterConsul, initConsulCenter)
+}
+
+// RegisterCenterAdapter 注册配置中心适配器, 通过-center参数选择
+func RegisterCenterAdapter(name string, adapter CenterAdapter) {
+	centerAdapters[name] = adapter
+}
+
+func GetCenterAdapterNames() []string {
+	var names []string
+	for name := range centerAdapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetCenterName 当前选择的配置中心/服务发现适配器
+func GetCenterName() string {
+	return center
+}
+
 func GetCenterConfig() *Center {
 	return centerConfig
 }
@@ -47,6 +97,9 @@ func (c *Center) FileType() string {
 
 func (c *Center) Init() {
 	c.initNacos()
+	c.initApollo()
+	c.Etcd.ServiceName = GetServiceName()
+	c.Consul.ServiceName = GetServiceName()
 }
 
 func (c *Center) initNacos() {
@@ -56,34 +109,160 @@ func (c *Center) initNacos() {
 	}
 }
 
-func initCenter() {
+func (c *Center) initApollo() {
+	c.Apollo.ServiceName = GetServiceName()
+	if utils.IsEmpty(c.Apollo.TempPath) {
+		c.Apollo.TempPath = utils.AddDirSuffixSlash(AppFilePath("temp/apollo"))
+	}
+}
+
+func initCenter() error {
 	if source != SourceCenter {
-		return
+		return nil
+	}
+	adapter, ok := centerAdapters[center]
+	if !ok {
+		return fmt.Errorf("unsupported config center: %s", center)
 	}
-	MapTo(centerConfig)
-	if center == CenterNacos {
-		initNacosCenter()
+	client, err := adapter(LoadCenterConfig())
+	if utils.HasErr(err) {
+		return err
 	}
+	centerClient = client
+	return nil
 }
 
-func initNacosCenter() {
-	nacos.InitConfig(GetCenterConfig().Nacos)
-	centerClient = nacos.GetConfigClient()
+// LoadCenterConfig 加载中心配置, 本地配置模式下服务发现同样依赖此配置
+func LoadCenterConfig() *Center {
+	if !centerLoaded {
+		MapTo(centerConfig)
+		centerLoaded = true
+	}
+	return centerConfig
 }
 
-func readFromCenter(cfg IConfig) ([]byte, error) {
-	name := cfg.Name()
-	content, err := centerClient.GetConfig(name)
-	if utils.HasErr(err) {
+func initNacosCenter(c *Center) (ICenter, error) {
+	if err := validateCenterConfig(c.Nacos); utils.HasErr(err) {
 		return nil, err
 	}
-	err = centerClient.ListenConfig(name, func(content string) {
-		_ = mapCfg([]byte(content), cfg)
-		log.Printf("[INFO] %s configuration reloaded successfully!\n", name)
-	})
-	if utils.HasErr(err) {
+	if err := nacos.InitConfig(c.Nacos); utils.HasErr(err) {
 		return nil, err
 	}
-	log.Printf("[INFO] listening %s configuration successfully!\n", name)
-	return []byte(content), nil
+	return nacos.GetConfigClient(), nil
+}
+
+func initApolloCenter(c *Center) (ICenter, error) {
+	if err := validateCenterConfig(c.Apollo); utils.HasErr(err) {
+		return nil, err
+	}
+	apollo.InitConfig(c.Apollo)
+	return apollo.GetConfigClient(), nil
+}
+
+func initEtcdCenter(c *Center) (ICenter, error) {
+	if err := validateCenterConfig(c.Etcd); utils.HasErr(err) {
+		return nil, err
+	}
+	etcd.InitConfig(c.Etcd)
+	return etcd.GetConfigClient(), nil
+}
+
+func initConsulCenter(c *Center) (ICenter, error) {
+	if err := validateCenterConfig(c.Consul); utils.HasErr(err) {
+		return nil, err
+	}
+	consul.InitConfig(c.Consul)
+	return consul.GetConfigClient(), nil
+}
+
+// CheckCenterConfig 校验选中适配器的配置
+func CheckCenterConfig(cfg interface{}) {
+	if err := validateCenterConfig(cfg); utils.HasErr(err) {
+		log.Fatalf("[FATAL] Init fatal: %+v\n", err)
+	}
+}
+
+func validateCenterConfig(cfg interface{}) error {
+	if err := validator.Check(cfg); utils.HasErr(err) {
+		return fmt.Errorf("check %s center conf err: %+v", center, err)
+	}
+	return nil
+}
+
+// readFromCenter 读取配置及各profile叠加配置(dataId为 <name>.<profile>), 任意一层变更时重新合并加载
+// 校验模式下只读取不监听
+func readFromCenter(cfg IConfig) ([]byte, error) {
+	name := cfg.Name()
+	names := layerNames(name)
+	layers := make([]string, len(names))
+	var mu sync.Mutex
+	if c, ok := centerClient.(IFileTypeCenter); ok {
+		for _, dataId := range names {
+			c.SetFileType(dataId, fileType(cfg))
+		}
+	}
+	for i, dataId := range names {
+		content, err := centerClient.GetConfig(dataId)
+		if i > 0 && isConfigNotFound(err) {
+			continue
+		}
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		layers[i] = content
+	}
+	merged, err := mergeLayers(cfg, layers)
+	if utils.HasErr(err) || checking {
+		return merged, err
+	}
+	for i, dataId := range names {
+		if i > 0 && utils.IsEmpty(layers[i]) {
+			continue
+		}
+		idx := i
+		err = centerClient.ListenConfig(dataId, func(content string) {
+			mu.Lock()
+			defer mu.Unlock()
+			layers[idx] = content
+			b, err := mergeLayers(cfg, layers)
+			if utils.HasErr(err) {
+				log.Printf("[ERROR] merge %s configuration err: %+v\n", name, err)
+				return
+			}
+			if err = reloadCfg(b, cfg); utils.HasErr(err) {
+				log.Printf("[ERROR] reload %s configuration err: %+v\n", name, err)
+				return
+			}
+			log.Printf("[INFO] %s configuration reloaded successfully!\n", name)
+		})
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		listening = append(listening, dataId)
+		log.Printf("[INFO] listening %s configuration successfully!\n", dataId)
+	}
+	return merged, nil
+}
+
+func mergeLayers(cfg IConfig, layers []string) ([]byte, error) {
+	content := []byte(layers[0])
+	var err error
+	for _, layer := range layers[1:] {
+		if utils.IsEmpty(layer) {
+			continue
+		}
+		content, err = mergeContent(fileType(cfg), content, []byte(layer))
+		if utils.HasErr(err) {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+func isConfigNotFound(err error) bool {
+	switch err {
+	case nacos.ErrConfigNotFound, apollo.ErrConfigNotFound, etcd.ErrConfigNotFound, consul.ErrConfigNotFound:
+		return true
+	}
+	return false
 }
@@ -28,7 +28,9 @@ func (c *Changeable) Init() {
 }
 
 func (c *Changeable) Persist(cfg IConfig) error {
+	RLock()
 	b, err := putContent(cfg)
+	RUnlock()
 	if utils.HasErr(err) {
 		return err
 	}
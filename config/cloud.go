@@ -20,7 +20,7 @@ type SmsTemplate struct {
 
 type SmsTemplateCapcha struct {
 	Id      string        `json:"id"`
-	Expired time.Duration `json:"expired" default:"2"`
+	Expired time.Duration `json:"expired" default:"2m" unit:"m"`
 }
 
 var CloudConfig = &Cloud{}
@@ -42,5 +42,4 @@ func (c *Cloud) FileType() string {
 }
 
 func (c *Cloud) Init() {
-	c.Sms.Templates.Capcha.Expired = c.Sms.Templates.Capcha.Expired * time.Minute
 }
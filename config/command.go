@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/EvisuXiao/andrews-common/pkg/validator"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// 配置命令, 用法: <binary> [flags] config schema|example|check
+const (
+	CommandName    = "config"
+	CommandSchema  = "schema"
+	CommandExample = "example"
+	CommandCheck   = "check"
+)
+
+// GetConfigs 已注册的配置
+func GetConfigs() []IConfig {
+	return configs
+}
+
+func isCommand() bool {
+	args := flag.Args()
+	return len(args) > 1 && args[0] == CommandName
+}
+
+// runCommand 执行配置命令后退出进程
+func runCommand() {
+	code := 0
+	switch flag.Arg(1) {
+	case CommandSchema:
+		code = printSchema()
+	case CommandExample:
+		code = printExample()
+	case CommandCheck:
+		code = printCheck()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command: %s, %s, %s, %s is available\n", flag.Arg(1), CommandSchema, CommandExample, CommandCheck)
+		code = 2
+	}
+	os.Exit(code)
+}
+
+func printSchema() int {
+	schemas := map[string]interface{}{centerConfig.Name(): Schema(centerConfig)}
+	for _, cfg := range configs {
+		schemas[cfg.Name()] = Schema(cfg)
+	}
+	b, _ := json.MarshalIndent(schemas, "", "  ")
+	fmt.Println(string(b))
+	return 0
+}
+
+func printExample() int {
+	for _, cfg := range append([]IConfig{centerConfig}, configs...) {
+		b, err := Example(cfg)
+		if utils.HasErr(err) {
+			fmt.Fprintf(os.Stderr, "generate %s example err: %+v\n", cfg.Name(), err)
+			return 1
+		}
+		fmt.Printf("# %s\n%s\n", configFilePath(cfg), b)
+	}
+	return 0
+}
+
+func printCheck() int {
+	errs := Check()
+	if utils.IsEmpty(errs) {
+		fmt.Println("All configuration is valid")
+		return 0
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Fprintf(os.Stderr, "%d configuration error(s) found\n", len(errs))
+	return 1
+}
+
+// Check 加载并校验全部配置, 返回所有错误而不是在首个错误时退出, 不监听配置变更
+func Check() []error {
+	checking = true
+	defer func() {
+		checking = false
+	}()
+	var errs []error
+	if source == SourceCenter {
+		errs = checkCfg(centerConfig)
+		if adapterCfg := centerAdapterConfig(); !utils.IsEmpty(adapterCfg) {
+			errs = append(errs, validationErrors(centerConfig.Name()+"."+center, adapterCfg)...)
+		}
+		if !utils.IsEmpty(errs) {
+			return errs
+		}
+		centerLoaded = true
+		if err := initCenter(); utils.HasErr(err) {
+			return append(errs, fmt.Errorf("init config center err: %+v", err))
+		}
+	}
+	for _, cfg := range configs {
+		errs = append(errs, checkCfg(cfg)...)
+	}
+	return errs
+}
+
+func checkCfg(cfg IConfig) []error {
+	name := cfg.Name()
+	content, err := readContent(cfg)
+	if utils.HasErr(err) {
+		return []error{fmt.Errorf("read conf %s err: %+v", name, err)}
+	}
+	if err = loadContent(content, cfg); utils.HasErr(err) {
+		return []error{fmt.Errorf("load conf %s err: %+v", name, err)}
+	}
+	errs := validationErrors(name, cfg)
+	if utils.IsEmpty(errs) {
+		cfg.Init()
+	}
+	return errs
+}
+
+func validationErrors(name string, v interface{}) []error {
+	fields := validator.CheckAll(v)
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		errs = append(errs, fmt.Errorf("check conf %s err: %s: %s", name, k, fields[k]))
+	}
+	return errs
+}
+
+// centerAdapterConfig 按json名称匹配当前适配器的配置
+func centerAdapterConfig() interface{} {
+	v := reflect.ValueOf(centerConfig).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if jsonFieldName(v.Type().Field(i)) == center {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
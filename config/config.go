@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/EvisuXiao/andrews-common/utils"
 )
@@ -16,6 +17,8 @@ const (
 
 	TypeJson = "json"
 	TypeYaml = "yaml"
+	TypeToml = "toml"
+	TypeEnv  = "env"
 )
 
 type IConfig interface {
@@ -25,13 +28,26 @@ type IConfig interface {
 	Init()
 }
 
+// IMultiTypeConfig 可选实现, 本地文件按FileTypes()顺序查找 conf/<name>.<type> 中存在的文件
+type IMultiTypeConfig interface {
+	IConfig
+	FileTypes() []string
+}
+
 var (
 	ServiceName string
 	dir         string
 	source      string
 	center      string
+	watch       bool
+	profile     string
 	configs     []IConfig
 	inited      bool
+	checking    bool
+
+	// resolvedTypes 本地文件实际命中的类型, 监听协程重新加载时同样会读写
+	resolvedTypes = make(map[string]string)
+	typesMu       sync.RWMutex
 )
 
 // Init 默认加载server, common配置
@@ -43,8 +59,14 @@ func Init(serviceName string, cfgs ...IConfig) {
 	configs = append(configs, cfgs...)
 	setServiceName(serviceName)
 	parseFlag()
-	initCenter()
+	if isCommand() {
+		runCommand()
+	}
+	if err := initCenter(); utils.HasErr(err) {
+		log.Fatalf("[FATAL] Init fatal: init config center err: %+v\n", err)
+	}
 	loadConf()
+	initWatcher()
 	log.Println("[INFO] All configuration loaded successfully!")
 	inited = true
 }
@@ -64,8 +86,11 @@ func RegisterConfig(cfg IConfig) {
 func parseFlag() {
 	flag.StringVar(&dir, "dir", "./", "The application directory")
 	flag.StringVar(&source, "source", SourceFile, fmt.Sprintf("The source of config file. %s, %s is available", SourceFile, SourceCenter))
-	flag.StringVar(&center, "center", CenterNacos, fmt.Sprintf("The config center adapter. %s is supported, %s is in the todo list", CenterNacos, CenterApollo))
+	flag.StringVar(&center, "center", CenterNacos, fmt.Sprintf("The config center and discovery adapter. %s is supported", strings.Join(GetCenterAdapterNames(), ", ")))
+	flag.BoolVar(&watch, "watch", false, "Reload local config files when they change")
+	flag.StringVar(&profile, "profile", "", fmt.Sprintf("The config profiles overlaid in order, separated by comma. Read from env %s if empty", ProfileEnvKey))
 	flag.Parse()
+	setProfiles(profile)
 	dir = utils.AddDirSuffixSlash(dir)
 	source = strings.ToLower(source)
 }
@@ -91,17 +116,17 @@ func MapTo(cfg IConfig) {
 }
 
 func Stop() error {
-	if source != SourceCenter {
-		return nil
+	if err := stopWatcher(); utils.HasErr(err) {
+		return err
 	}
-	for _, cfg := range configs {
-		name := cfg.Name()
+	for _, name := range listening {
 		err := centerClient.CancelListenConfig(name)
 		if utils.HasErr(err) {
 			return err
 		}
 		log.Printf("[INFO] cancel listening %s configuration successfully!\n", name)
 	}
+	listening = nil
 	return nil
 }
 
@@ -12,7 +12,7 @@ type Database struct {
 	Slave        *DatabaseConnection
 	TablePrefix  string
 	PoolSize     int           `default:"50"`
-	PoolLifeTime time.Duration `default:"3600"`
+	PoolLifeTime time.Duration `default:"1h" unit:"s"`
 }
 type DatabaseConnection struct {
 	Host     string
@@ -41,7 +41,4 @@ func (c *Databases) FileType() string {
 }
 
 func (c *Databases) Init() {
-	for _, db := range *DatabaseConfigs {
-		db.PoolLifeTime = db.PoolLifeTime * time.Second
-	}
 }
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// durationUnit 字段unit标签指定的纯数字时长单位, 如 unit:"s", 未指定时返回0, 纯数字按纳秒处理
+func durationUnit(field reflect.StructField) time.Duration {
+	u := field.Tag.Get("unit")
+	if utils.IsEmpty(u) {
+		return 0
+	}
+	d, err := time.ParseDuration("1" + u)
+	if utils.HasErr(err) {
+		return 0
+	}
+	return d
+}
+
+type fieldMatcher func(field reflect.StructField, key string) bool
+
+func matchJsonField(field reflect.StructField, key string) bool {
+	return strings.EqualFold(jsonFieldName(field), key)
+}
+
+func matchYamlField(field reflect.StructField, key string) bool {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if utils.IsEmpty(name) {
+		name = strings.ToLower(field.Name)
+	}
+	return name == key
+}
+
+// decodeJson 数字解码为json.Number, 避免经由通用结构转换时超过2^53的整数丢失精度
+func decodeJson(content []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); utils.HasErr(err) {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+func loadJson(content []byte, cfg IConfig) error {
+	var m interface{}
+	if err := decodeJson(content, &m); utils.HasErr(err) {
+		return err
+	}
+	m = walkDurations(m, reflect.TypeOf(cfg), 0, matchJsonField, parseDuration)
+	if err := json.Unmarshal(utils.EncodeJsonValue(m), cfg); utils.HasErr(err) {
+		return err
+	}
+	utils.SetStructDefaultValueBySource(cfg, m, matchJsonField)
+	return nil
+}
+
+func loadYaml(content []byte, cfg IConfig) error {
+	var m interface{}
+	if err := yaml.Unmarshal(content, &m); utils.HasErr(err) {
+		return err
+	}
+	m = walkDurations(m, reflect.TypeOf(cfg), 0, matchYamlField, parseDuration)
+	b, err := yaml.Marshal(m)
+	if utils.HasErr(err) {
+		return err
+	}
+	if err = yaml.Unmarshal(b, cfg); utils.HasErr(err) {
+		return err
+	}
+	utils.SetStructDefaultValueBySource(cfg, m, matchYamlField)
+	return nil
+}
+
+// jsonMap 转为json结构的通用map, 时长格式化为 "1m0s" 以便持久化后可以重新加载
+func jsonMap(cfg IConfig) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := decodeJson(utils.EncodeJsonValue(cfg), &m); utils.HasErr(err) {
+		return nil, err
+	}
+	m, _ = walkDurations(m, reflect.TypeOf(cfg), 0, matchJsonField, formatDuration).(map[string]interface{})
+	return m, nil
+}
+
+func putJson(cfg IConfig) ([]byte, error) {
+	m, err := jsonMap(cfg)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func putYaml(cfg IConfig) ([]byte, error) {
+	b, err := yaml.Marshal(cfg)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	var m interface{}
+	if err = yaml.Unmarshal(b, &m); utils.HasErr(err) {
+		return nil, err
+	}
+	return yaml.Marshal(walkDurations(m, reflect.TypeOf(cfg), 0, matchYamlField, formatDuration))
+}
+
+// walkDurations 按配置结构遍历通用map, 对time.Duration字段的值进行转换
+func walkDurations(v interface{}, t reflect.Type, unit time.Duration, match fieldMatcher, convert func(interface{}, time.Duration) interface{}) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return convert(v, unit)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		walkStructDurations(m, t, match, convert)
+	case reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			for k, item := range m {
+				m[k] = walkDurations(item, t.Elem(), unit, match, convert)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if l, ok := v.([]interface{}); ok {
+			for i, item := range l {
+				l[i] = walkDurations(item, t.Elem(), unit, match, convert)
+			}
+		}
+	}
+	return v
+}
+
+func walkStructDurations(m map[string]interface{}, t reflect.Type, match fieldMatcher, convert func(interface{}, time.Duration) interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !utils.IsEmpty(field.PkgPath) {
+			continue
+		}
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				walkStructDurations(m, ft, match, convert)
+			}
+			continue
+		}
+		for k, item := range m {
+			if match(field, k) {
+				m[k] = walkDurations(item, field.Type, durationUnit(field), match, convert)
+			}
+		}
+	}
+}
+
+// parseDuration 字符串按 "60s" 格式解析, 数字按单位换算, 统一为纳秒
+// 未指定单位的纯数字保持原值, 与直接解码到time.Duration一致, 已在Init中自行换算的配置不受影响
+func parseDuration(v interface{}, unit time.Duration) interface{} {
+	if unit <= 0 {
+		if s, ok := v.(string); ok {
+			if d, err := time.ParseDuration(s); !utils.HasErr(err) {
+				return int64(d)
+			}
+		}
+		return v
+	}
+	switch val := v.(type) {
+	case string:
+		if d, err := time.ParseDuration(val); !utils.HasErr(err) {
+			return int64(d)
+		}
+	case json.Number:
+		if n, err := val.Int64(); !utils.HasErr(err) {
+			return n * int64(unit)
+		}
+		if f, err := val.Float64(); !utils.HasErr(err) {
+			return int64(f * float64(unit))
+		}
+	case float64:
+		return int64(val * float64(unit))
+	case int:
+		return int64(val) * int64(unit)
+	case int64:
+		return val * int64(unit)
+	}
+	return v
+}
+
+func formatDuration(v interface{}, _ time.Duration) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); !utils.HasErr(err) {
+			return time.Duration(n).String()
+		}
+	case float64:
+		return time.Duration(val).String()
+	case int:
+		return time.Duration(val).String()
+	case int64:
+		return time.Duration(val).String()
+	}
+	return v
+}
+
+// plainNumbers 将json.Number转为int64或float64, 用于不识别json.Number的编码器
+func plainNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); !utils.HasErr(err) {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = plainNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = plainNumbers(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// envSeparator dotenv中嵌套字段的分隔符, 如 TIMEOUT__READ=60 对应 {"timeout": {"read": 60}}
+const envSeparator = "__"
+
+// loadEnv 按配置结构的json名称将dotenv转换为json后加载
+func loadEnv(content []byte, cfg IConfig) error {
+	values, err := parseEnv(content)
+	if utils.HasErr(err) {
+		return err
+	}
+	m := envToValue(values, reflect.TypeOf(cfg), "")
+	if utils.IsEmpty(m) {
+		m = make(map[string]interface{})
+	}
+	return loadJson(utils.EncodeJsonValue(m), cfg)
+}
+
+func putEnv(cfg IConfig) ([]byte, error) {
+	m, err := jsonMap(cfg)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	lines := make(map[string]string)
+	flattenEnv(m, "", lines)
+	return encodeEnvLines(lines), nil
+}
+
+func encodeEnvLines(lines map[string]string) []byte {
+	var keys []string
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(fmt.Sprintf("%s=%s\n", k, lines[k]))
+	}
+	return buf.Bytes()
+}
+
+func parseEnv(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if utils.IsEmpty(text) || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		idx := strings.Index(text, "=")
+		if idx < 1 {
+			return nil, fmt.Errorf("invalid env line %d: %s", line, text)
+		}
+		key := strings.ToUpper(strings.TrimSpace(text[:idx]))
+		val := strings.TrimSpace(text[idx+1:])
+		if len(val) > 1 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			if val[0] == '"' {
+				if unquoted, err := strconv.Unquote(val); !utils.HasErr(err) {
+					val = unquoted
+				}
+			} else {
+				val = val[1 : len(val)-1]
+			}
+		} else if i := strings.Index(val, " #"); i > -1 {
+			val = strings.TrimSpace(val[:i])
+		}
+		values[key] = val
+	}
+	return values, scanner.Err()
+}
+
+// envToValue 按目标类型转换dotenv的值, 未出现的字段不输出以保留默认值
+func envToValue(values map[string]string, t reflect.Type, prefix string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.String() != "time.Time" {
+		m := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !utils.IsEmpty(field.PkgPath) {
+				continue
+			}
+			if field.Anonymous {
+				if sub, ok := envToValue(values, field.Type, prefix).(map[string]interface{}); ok {
+					m = utils.MergeMap(m, sub)
+				}
+				continue
+			}
+			name := jsonFieldName(field)
+			if name == "-" {
+				continue
+			}
+			if v := envToValue(values, field.Type, envKey(prefix, name)); v != nil {
+				m[name] = v
+			}
+		}
+		if utils.IsEmpty(m) {
+			return nil
+		}
+		return m
+	}
+	if t.Kind() == reflect.Map {
+		m := make(map[string]interface{})
+		// map的键统一转为小写
+		for key := range values {
+			rest := key
+			if !utils.IsEmpty(prefix) {
+				if !strings.HasPrefix(key, prefix+envSeparator) {
+					continue
+				}
+				rest = strings.TrimPrefix(key, prefix+envSeparator)
+			}
+			name := strings.ToLower(strings.Split(rest, envSeparator)[0])
+			if _, ok := m[name]; ok {
+				continue
+			}
+			if v := envToValue(values, t.Elem(), envKey(prefix, name)); v != nil {
+				m[name] = v
+			}
+		}
+		if utils.IsEmpty(m) {
+			return nil
+		}
+		return m
+	}
+	val, ok := values[prefix]
+	if !ok {
+		return nil
+	}
+	if t.Kind() == reflect.String {
+		return val
+	}
+	if t.Kind() == reflect.Slice && !strings.HasPrefix(val, "[") {
+		var items []interface{}
+		for _, item := range strings.Split(val, ",") {
+			items = append(items, rawEnvValue(strings.TrimSpace(item), t.Elem()))
+		}
+		return items
+	}
+	return rawEnvValue(val, t)
+}
+
+func rawEnvValue(val string, t reflect.Type) interface{} {
+	if t.Kind() == reflect.String {
+		return val
+	}
+	var v interface{}
+	if err := decodeJson([]byte(val), &v); utils.HasErr(err) {
+		return val
+	}
+	return v
+}
+
+func flattenEnv(v interface{}, prefix string, lines map[string]string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range val {
+			flattenEnv(sub, envKey(prefix, k), lines)
+		}
+	case nil:
+	case string:
+		if strings.ContainsAny(val, " #\"'\n") {
+			lines[prefix] = strconv.Quote(val)
+		} else {
+			lines[prefix] = val
+		}
+	default:
+		lines[prefix] = string(utils.EncodeJsonValue(val))
+	}
+}
+
+func envKey(prefix, name string) string {
+	name = strings.ToUpper(name)
+	if utils.IsEmpty(prefix) {
+		return name
+	}
+	return prefix + envSeparator + name
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if utils.IsEmpty(name) {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const secretMask = "******"
+
+// State 配置当前生效的内容, 敏感字段已脱敏
+type State struct {
+	Name     string      `json:"name"`
+	Source   string      `json:"source"`
+	Location string      `json:"location"`
+	Profiles []string    `json:"profiles"`
+	LoadedAt time.Time   `json:"loaded_at"`
+	Content  interface{} `json:"content"`
+}
+
+var (
+	loadedAt   = make(map[string]time.Time)
+	loadedMu   sync.RWMutex
+	secretName = regexp.MustCompile(`(?i:password|passwd|secret|token|credential)|(^|_)(?i:key)$|Key$`)
+)
+
+func markLoaded(cfg IConfig) {
+	loadedMu.Lock()
+	loadedAt[cfg.Name()] = time.Now()
+	loadedMu.Unlock()
+}
+
+// GetStates 已注册配置的当前状态, 包含来源及最后一次加载时间
+func GetStates() []*State {
+	states := make([]*State, 0, len(configs)+1)
+	if centerLoaded {
+		states = append(states, GetState(centerConfig))
+	}
+	for _, cfg := range configs {
+		states = append(states, GetState(cfg))
+	}
+	return states
+}
+
+func GetState(cfg IConfig) *State {
+	loadedMu.RLock()
+	t := loadedAt[cfg.Name()]
+	loadedMu.RUnlock()
+	s := readSource(cfg)
+	location := configFilePath(cfg)
+	if s == SourceCenter {
+		location = fmt.Sprintf("%s:%s", center, strings.Join(layerNames(cfg.Name()), ","))
+	}
+	RLock()
+	content := Mask(cfg)
+	RUnlock()
+	return &State{
+		Name:     cfg.Name(),
+		Source:   s,
+		Location: location,
+		Profiles: profiles,
+		LoadedAt: t,
+		Content:  content,
+	}
+}
+
+// Mask 转为通用结构并隐藏敏感字段, 字段带有secret标签或名称包含password/secret/token/key等视为敏感
+func Mask(v interface{}) interface{} {
+	return maskValue(reflect.ValueOf(v), false)
+}
+
+func maskValue(v reflect.Value, secret bool) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == durationType {
+		return v.Interface().(time.Duration).String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t
+		}
+		m := make(map[string]interface{})
+		maskStruct(v, secret, m)
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{})
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			m[key] = maskValue(iter.Value(), secret || secretName.MatchString(key))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		l := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			l[i] = maskValue(v.Index(i), secret)
+		}
+		return l
+	}
+	if secret && !v.IsZero() {
+		return secretMask
+	}
+	return v.Interface()
+}
+
+func maskStruct(v reflect.Value, secret bool, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !utils.IsEmpty(field.PkgPath) {
+			continue
+		}
+		if field.Anonymous {
+			fv := reflect.Indirect(v.Field(i))
+			if fv.Kind() == reflect.Struct {
+				maskStruct(fv, secret, m)
+			}
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		_, tagged := field.Tag.Lookup("secret")
+		m[name] = maskValue(v.Field(i), secret || tagged || secretName.MatchString(field.Name) || secretName.MatchString(name))
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// ProfileEnvKey 未指定-profile参数时从该环境变量读取
+const ProfileEnvKey = "APP_PROFILE"
+
+var profiles []string
+
+func GetProfiles() []string {
+	return profiles
+}
+
+func setProfiles(p string) {
+	if utils.IsEmpty(p) {
+		p = os.Getenv(ProfileEnvKey)
+	}
+	profiles = nil
+	for _, item := range strings.Split(p, ",") {
+		item = strings.TrimSpace(item)
+		if !utils.IsEmpty(item) {
+			profiles = append(profiles, item)
+		}
+	}
+}
+
+// layerNames 配置名及按profile依次叠加的配置名, 如 database, database.production
+func layerNames(name string) []string {
+	names := []string{name}
+	for _, p := range profiles {
+		names = append(names, fmt.Sprintf("%s.%s", name, p))
+	}
+	return names
+}
+
+// mergeContent 将overlay深度合并到base, 对象逐键合并, 其他类型整体覆盖
+func mergeContent(fileType string, base, overlay []byte) ([]byte, error) {
+	if fileType == TypeEnv {
+		return append(append(base, '\n'), overlay...), nil
+	}
+	baseMap, err := decodeMap(fileType, base)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	overlayMap, err := decodeMap(fileType, overlay)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	return encodeMap(fileType, deepMerge(baseMap, overlayMap))
+}
+
+func deepMerge(base, overlay map[string]interface{}) map[string]interface{} {
+	if base == nil {
+		base = make(map[string]interface{})
+	}
+	for k, v := range overlay {
+		if sub, ok := v.(map[string]interface{}); ok {
+			if baseSub, ok := base[k].(map[string]interface{}); ok {
+				base[k] = deepMerge(baseSub, sub)
+				continue
+			}
+		}
+		base[k] = v
+	}
+	return base
+}
+
+func decodeMap(fileType string, content []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	switch fileType {
+	case TypeJson:
+		return m, decodeJson(content, &m)
+	case TypeYaml:
+		return m, yaml.Unmarshal(content, &m)
+	case TypeToml:
+		tree, err := toml.LoadBytes(content)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		return tree.ToMap(), nil
+	default:
+		return nil, fmt.Errorf("invalid file type: %s", fileType)
+	}
+}
+
+func encodeMap(fileType string, m map[string]interface{}) ([]byte, error) {
+	switch fileType {
+	case TypeJson:
+		return json.Marshal(m)
+	case TypeYaml:
+		return yaml.Marshal(m)
+	case TypeToml:
+		tree, err := toml.TreeFromMap(m)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		return []byte(tree.String()), nil
+	default:
+		return nil, fmt.Errorf("invalid file type: %s", fileType)
+	}
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Schema 根据配置结构生成JSON Schema, 包含default标签的默认值及binding标签的约束
+func Schema(cfg IConfig) map[string]interface{} {
+	s := typeSchema(reflect.TypeOf(cfg))
+	s["$schema"] = schemaDraft
+	s["title"] = cfg.Name()
+	return s
+}
+
+// Example 生成填充默认值的配置示例, map及slice各保留一个元素以展示结构
+func Example(cfg IConfig) ([]byte, error) {
+	t := fileType(cfg)
+	v := exampleValue(reflect.TypeOf(cfg), "")
+	m, _ := v.(map[string]interface{})
+	switch t {
+	case TypeJson:
+		return json.MarshalIndent(m, "", "  ")
+	case TypeEnv:
+		lines := make(map[string]string)
+		flattenEnv(m, "", lines)
+		return encodeEnvLines(lines), nil
+	}
+	return encodeMap(t, m)
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := make(map[string]interface{})
+	if t == durationType {
+		s["type"] = []string{"string", "integer"}
+		s["description"] = "duration such as 60s, bare numbers are in the unit of the field unit tag, or nanoseconds without it"
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.String() == "time.Time" {
+			s["type"] = "string"
+			s["format"] = "date-time"
+			return s
+		}
+		props := make(map[string]interface{})
+		var required []string
+		collectProperties(t, props, &required)
+		s["type"] = "object"
+		s["properties"] = props
+		if !utils.IsEmpty(required) {
+			s["required"] = required
+		}
+	case reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = typeSchema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		s["type"] = "array"
+		s["items"] = typeSchema(t.Elem())
+	case reflect.String:
+		s["type"] = "string"
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+	}
+	return s
+}
+
+func collectProperties(t reflect.Type, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !utils.IsEmpty(field.PkgPath) {
+			continue
+		}
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectProperties(ft, props, required)
+			}
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		fs := typeSchema(field.Type)
+		if def := field.Tag.Get("default"); !utils.IsEmpty(def) {
+			fs["default"] = defaultValue(def, field.Type)
+		}
+		if applyBinding(fs, field.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		props[name] = fs
+	}
+}
+
+// applyBinding 将常用的binding约束转为schema关键字, 返回是否必填
+func applyBinding(s map[string]interface{}, binding string) bool {
+	if utils.IsEmpty(binding) || binding == "-" {
+		return false
+	}
+	s["x-binding"] = binding
+	required := false
+	isNumber := s["type"] == "integer" || s["type"] == "number"
+	for _, rule := range strings.Split(binding, ",") {
+		kv := strings.SplitN(rule, "=", 2)
+		param := ""
+		if len(kv) > 1 {
+			param = kv[1]
+		}
+		num, _ := strconv.ParseFloat(param, 64)
+		switch kv[0] {
+		case "required":
+			required = true
+		case "gt":
+			if isNumber {
+				s["exclusiveMinimum"] = num
+			}
+		case "gte", "min":
+			switch s["type"] {
+			case "string":
+				s["minLength"] = num
+			case "array":
+				s["minItems"] = num
+			default:
+				s["minimum"] = num
+			}
+		case "lt":
+			if isNumber {
+				s["exclusiveMaximum"] = num
+			}
+		case "lte", "max":
+			switch s["type"] {
+			case "string":
+				s["maxLength"] = num
+			case "array":
+				s["maxItems"] = num
+			default:
+				s["maximum"] = num
+			}
+		case "oneof":
+			var enum []interface{}
+			for _, item := range strings.Fields(param) {
+				enum = append(enum, item)
+			}
+			s["enum"] = enum
+		case "email", "url", "uri", "hostname", "ipv4", "ipv6":
+			s["format"] = kv[0]
+		}
+	}
+	return required
+}
+
+func exampleValue(t reflect.Type, def string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.String() == "time.Time" {
+			return utils.LocalTimeStr(utils.LocalTime())
+		}
+		m := make(map[string]interface{})
+		collectExample(t, m)
+		return m
+	}
+	if !utils.IsEmpty(def) {
+		return defaultValue(def, t)
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return map[string]interface{}{"example": exampleValue(t.Elem(), "")}
+	case reflect.Slice, reflect.Array:
+		return []interface{}{exampleValue(t.Elem(), "")}
+	}
+	return reflect.Zero(t).Interface()
+}
+
+func collectExample(t reflect.Type, m map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !utils.IsEmpty(field.PkgPath) {
+			continue
+		}
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectExample(ft, m)
+			}
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		def := field.Tag.Get("default")
+		if utils.IsEmpty(def) {
+			def = bindingOption(field.Tag.Get("binding"))
+		}
+		m[name] = exampleValue(field.Type, def)
+	}
+}
+
+// bindingOption oneof约束的首个可选值, 无默认值时作为示例值
+func bindingOption(binding string) string {
+	for _, rule := range strings.Split(binding, ",") {
+		if !strings.HasPrefix(rule, "oneof=") {
+			continue
+		}
+		if options := strings.Fields(strings.TrimPrefix(rule, "oneof=")); !utils.IsEmpty(options) {
+			return options[0]
+		}
+	}
+	return ""
+}
+
+// defaultValue 按加载配置时的规则解析默认值, 时长保持原始写法, 其他值转为json对应的类型
+func defaultValue(def string, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return rawEnvValue(def, t)
+	}
+	var v interface{}
+	if err := decodeJson(utils.EncodeJsonValue(utils.ParseDefaultValue(t, def).Interface()), &v); utils.HasErr(err) {
+		return def
+	}
+	return v
+}
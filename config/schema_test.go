@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestExampleMatchesSchema 已注册配置生成的示例及各字段默认值均需符合生成的schema
+func TestExampleMatchesSchema(t *testing.T) {
+	for _, cfg := range append([]IConfig{centerConfig}, GetConfigs()...) {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(mustMarshal(t, Schema(cfg)), &schema); err != nil {
+			t.Fatalf("decode %s schema err: %v", cfg.Name(), err)
+		}
+		b, err := Example(cfg)
+		if err != nil {
+			t.Fatalf("generate %s example err: %v", cfg.Name(), err)
+		}
+		var example interface{}
+		if err = json.Unmarshal(b, &example); err != nil {
+			t.Fatalf("decode %s example err: %v", cfg.Name(), err)
+		}
+		for _, msg := range validateSchema(schema, example, cfg.Name()) {
+			t.Error(msg)
+		}
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	methods := Schema(ServerConfig)["properties"].(map[string]interface{})["cors"].(map[string]interface{})["properties"].(map[string]interface{})["allow_methods"].(map[string]interface{})
+	if got := fmt.Sprint(methods["default"]); got != "[GET POST PUT PATCH DELETE HEAD OPTIONS]" {
+		t.Fatalf("cors.allow_methods default = %s, want array", got)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// validateSchema 校验生成schema中用到的关键字, 返回不符合的路径
+func validateSchema(s map[string]interface{}, v interface{}, path string) []string {
+	var errs []string
+	if def, ok := s["default"]; ok {
+		sub := make(map[string]interface{})
+		for k, item := range s {
+			if k != "default" {
+				sub[k] = item
+			}
+		}
+		errs = append(errs, validateSchema(sub, def, path+"(default)")...)
+	}
+	if !matchType(s["type"], v) {
+		return append(errs, fmt.Sprintf("%s: %v does not match type %v", path, v, s["type"]))
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		props, _ := s["properties"].(map[string]interface{})
+		for k, item := range val {
+			sub, ok := props[k].(map[string]interface{})
+			if !ok {
+				sub, _ = s["additionalProperties"].(map[string]interface{})
+			}
+			if sub == nil {
+				errs = append(errs, fmt.Sprintf("%s.%s: property is not declared", path, k))
+				continue
+			}
+			errs = append(errs, validateSchema(sub, item, path+"."+k)...)
+		}
+		required, _ := s["required"].([]interface{})
+		for _, k := range required {
+			if _, ok := val[k.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: required property is missing", path, k))
+			}
+		}
+	case []interface{}:
+		items, _ := s["items"].(map[string]interface{})
+		for i, item := range val {
+			errs = append(errs, validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !containsValue(enum, v) {
+		errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", path, v, enum))
+	}
+	return errs
+}
+
+func matchType(t interface{}, v interface{}) bool {
+	switch tp := t.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		for _, item := range tp {
+			if matchType(item, v) {
+				return true
+			}
+		}
+		return false
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return t == "object"
+	case []interface{}:
+		return t == "array"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || (t == "integer" && val == math.Trunc(val))
+	case nil:
+		return t == "object" || t == "array"
+	}
+	return false
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
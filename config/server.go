@@ -16,11 +16,159 @@ type Server struct {
 	Weight    float64 `json:"weight" default:"100"`
 	Timeout   Timeout `json:"timeout"`
 	RateLimit int     `json:"rate_limit"`
+	// RateLimits redis分布式限流策略, 在middleware中以 rate_limit:<策略名> 启用
+	RateLimits map[string]*RateLimitPolicy `json:"rate_limits" binding:"dive"`
+	Log        Log                         `json:"log"`
+	Trace      Trace                       `json:"trace"`
+	Metrics    Metrics                     `json:"metrics"`
+	// Middleware 按名称启用http中间件
+	Middleware Middleware `json:"middleware"`
+	Auth       Auth       `json:"auth"`
+	Cors       Cors       `json:"cors"`
+	Security   Security   `json:"security"`
+	// TrustedProxies 可信代理的IP或CIDR, 仅来自这些地址的X-Forwarded-For及X-Real-Ip用于获取客户端ip
+	// 为空时按ip限流使用连接的对端地址, 避免客户端伪造请求头绕过限流
+	TrustedProxies []string `json:"trusted_proxies"`
+	// MaxBodySize 请求体最大字节数, 需在middleware中启用body_limit, 0为不限制
+	MaxBodySize int64  `json:"max_body_size" binding:"gte=0"`
+	Docs        Docs   `json:"docs"`
+	Health      Health `json:"health"`
 }
 type Timeout struct {
-	Read  time.Duration `json:"read" default:"60"`
-	Write time.Duration `json:"write" default:"60"`
-	Exit  time.Duration `json:"exit" default:"3"`
+	Read  time.Duration `json:"read" default:"60s" unit:"s"`
+	Write time.Duration `json:"write" default:"60s" unit:"s"`
+	Exit  time.Duration `json:"exit" default:"3s" unit:"s"`
+	// Request 请求处理超时, 到期后请求上下文取消并立即返回超时错误
+	// 需在middleware中启用timeout, 0为不限制, 路由可单独设置
+	Request time.Duration `json:"request" unit:"s"`
+	// Drain 停止时先将就绪检查置为失败, 等待负载均衡摘除流量后再关闭服务, 0为不等待
+	Drain time.Duration `json:"drain" default:"5s" unit:"s"`
+}
+type Log struct {
+	Level  string `json:"level" default:"debug" binding:"oneof=debug info warning error fatal"`
+	Format string `json:"format" default:"text" binding:"oneof=text json"`
+	// Sinks 为空时输出到标准错误
+	Sinks  []LogSink `json:"sinks" binding:"dive"`
+	Access AccessLog `json:"access"`
+}
+
+// LogSink 日志输出目标, level及format为空时使用上层配置, 如单独输出错误日志可设置level为error
+type LogSink struct {
+	Type   string `json:"type" default:"stdout" binding:"oneof=stdout stderr file"`
+	Level  string `json:"level" binding:"omitempty,oneof=debug info warning error fatal"`
+	Format string `json:"format" binding:"omitempty,oneof=text json"`
+	// Path 相对路径基于应用目录
+	Path string `json:"path" default:"logs/app.log"`
+	// MaxSize 单个文件最大MB数, 0为不按大小轮转
+	MaxSize  int           `json:"max_size"`
+	Interval time.Duration `json:"interval" unit:"s"`
+	MaxAge   time.Duration `json:"max_age" unit:"s"`
+	MaxCount int           `json:"max_count"`
+	Compress bool          `json:"compress"`
+}
+
+// Trace 链路追踪, exporter为空时只传播链路不导出
+type Trace struct {
+	Exporter   string  `json:"exporter" binding:"omitempty,oneof=stdout otlp"`
+	Endpoint   string  `json:"endpoint" default:"http://127.0.0.1:4318/v1/traces"`
+	SampleRate float64 `json:"sample_rate" default:"1" binding:"gte=0,lte=1"`
+}
+
+// Metrics prometheus指标, port为0时http服务在path暴露, grpc服务需设置port单独暴露
+type Metrics struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path" default:"/metrics"`
+	Port    int    `json:"port" binding:"gte=0,lt=65536"`
+}
+
+// Middleware 按列表顺序执行, 未开启的功能(如rate_limit为0)自动跳过
+// 默认与原有全局中间件一致, cors, security_headers, body_limit, timeout需在global或groups中按名称启用
+type Middleware struct {
+	Global []string `json:"global" default:"trace,access_log,metrics,recovery,rate_limit"`
+	// Groups 路由组完整路径对应的中间件, 在代码中声明的中间件之前执行
+	Groups map[string][]string `json:"groups"`
+}
+
+// RateLimitPolicy 每period允许rate次请求, burst为0时等于rate
+// key可选ip, user(未登录时按ip), api_key(读取header指定的请求头), 或通过http.RegisterRateLimitKey注册的名称
+type RateLimitPolicy struct {
+	Redis  string        `json:"redis" binding:"required"`
+	Rate   int           `json:"rate" binding:"gt=0"`
+	Period time.Duration `json:"period" default:"1s" unit:"s"`
+	Burst  int           `json:"burst" binding:"gte=0"`
+	Key    string        `json:"key" default:"ip"`
+	Header string        `json:"header" default:"X-Api-Key"`
+}
+
+// Auth jwt鉴权, secret, keys及jwks_url均为空时不启用auth中间件, redis为吊销列表使用的连接名称, 为空时无法注销
+type Auth struct {
+	Secret string `json:"secret"`
+	// Keys 非对称密钥, 配置后不再使用secret, signing_key为空时使用第一个私钥签名, 其余密钥在轮换期间用于验签
+	Keys       []AuthKey `json:"keys" binding:"dive"`
+	SigningKey string    `json:"signing_key"`
+	// JwksUrl 不签发令牌的服务从签发服务的JWKS接口获取验签公钥
+	JwksUrl      string        `json:"jwks_url"`
+	JwksCacheTtl time.Duration `json:"jwks_cache_ttl" default:"10m" unit:"s"`
+	// Issuer 签发时写入并校验, audience签发时使用第一个, 校验时接受其中任一
+	Issuer   string   `json:"issuer"`
+	Audience []string `json:"audience"`
+	// Leeway 校验有效期时允许的时钟偏差
+	Leeway         time.Duration `json:"leeway" default:"30s" unit:"s"`
+	AccessExpired  time.Duration `json:"access_expired" default:"2h" unit:"s"`
+	RefreshExpired time.Duration `json:"refresh_expired" default:"168h" unit:"s"`
+	Cookie         string        `json:"cookie" default:"access_token"`
+	Redis          string        `json:"redis"`
+}
+
+type AuthKey struct {
+	Id        string `json:"id" binding:"required"`
+	Algorithm string `json:"algorithm" binding:"oneof=RS256 RS384 RS512 PS256 PS384 PS512 ES256 ES384 ES512 EdDSA"`
+	// Path PEM文件路径, 相对路径基于应用目录, 公钥仅用于验签
+	Path string `json:"path" binding:"required"`
+}
+
+// Cors 跨域, 需在middleware中启用cors, allow_origins为空时不生效, 支持*及https://*.example.com形式的子域名通配
+// 开启allow_credentials时不返回*而是回写请求的Origin
+type Cors struct {
+	AllowOrigins     []string      `json:"allow_origins"`
+	AllowMethods     []string      `json:"allow_methods" default:"GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"`
+	AllowHeaders     []string      `json:"allow_headers" default:"Origin,Content-Type,Accept,Authorization,X-Request-Id,X-Api-Key"`
+	ExposeHeaders    []string      `json:"expose_headers" default:"X-Request-Id,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After"`
+	AllowCredentials bool          `json:"allow_credentials"`
+	MaxAge           time.Duration `json:"max_age" default:"12h" unit:"s"`
+}
+
+// Security 安全响应头, 需在middleware中启用security_headers, 值为空的响应头不输出, hsts仅在https请求中输出
+type Security struct {
+	Hsts                  time.Duration `json:"hsts" unit:"s"`
+	HstsIncludeSubdomains bool          `json:"hsts_include_subdomains"`
+	ContentTypeOptions    string        `json:"content_type_options" default:"nosniff"`
+	FrameOptions          string        `json:"frame_options" default:"DENY"`
+	ReferrerPolicy        string        `json:"referrer_policy" default:"strict-origin-when-cross-origin"`
+	ContentSecurityPolicy string        `json:"content_security_policy"`
+}
+
+// Health 服务发现连接默认计入就绪检查, 不影响存活检查
+type Health struct {
+	// Discovery 为false时不将服务发现连接计入就绪检查, 适用于服务发现不可用时仍需接收流量的部署
+	Discovery bool `json:"discovery" default:"true"`
+}
+
+// Docs 按路由声明生成OpenAPI文档, path为Swagger UI地址, 文档地址为 <path>/openapi.json
+type Docs struct {
+	Enabled     bool   `json:"enabled"`
+	Path        string `json:"path" default:"/docs"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version" default:"1.0.0"`
+}
+
+// AccessLog http访问日志, 状态码5xx的请求不受采样影响
+type AccessLog struct {
+	Disabled   bool    `json:"disabled"`
+	SampleRate float64 `json:"sample_rate" default:"1" binding:"gte=0,lte=1"`
+	// Exclude 不记录的路径, 以*结尾时按前缀匹配
+	Exclude []string `json:"exclude"`
 }
 
 var ServerConfig = &Server{}
@@ -49,9 +197,6 @@ func (c *Server) Init() {
 	if c.Env != EnvLocal && c.Env != EnvProd {
 		c.Env = EnvTesting
 	}
-	c.Timeout.Read = c.Timeout.Read * time.Second
-	c.Timeout.Write = c.Timeout.Write * time.Second
-	c.Timeout.Exit = c.Timeout.Exit * time.Second
 }
 
 func IsLocalEnv() bool {
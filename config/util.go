@@ -1,24 +1,45 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 
-	"gopkg.in/yaml.v3"
+	"github.com/pelletier/go-toml"
 
 	"github.com/EvisuXiao/andrews-common/pkg/validator"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
+var cfgMu sync.RWMutex
+
+// RLock 热更新时整体替换正在使用的配置, 需一致读取多个字段时在读锁内进行
+func RLock() {
+	cfgMu.RLock()
+}
+
+func RUnlock() {
+	cfgMu.RUnlock()
+}
+
 func mapCfg(content []byte, cfg IConfig) error {
+	err := buildCfg(content, cfg)
+	if utils.HasErr(err) {
+		return err
+	}
+	markLoaded(cfg)
+	return nil
+}
+
+// buildCfg 加载内容并为未给出的字段填充默认值, 校验后初始化
+func buildCfg(content []byte, cfg IConfig) error {
 	err := loadContent(content, cfg)
 	if utils.HasErr(err) {
 		return err
 	}
-	utils.SetStructDefaultValue(cfg)
 	err = validator.Check(cfg)
 	if utils.HasErr(err) {
 		return err
@@ -27,45 +48,158 @@ func mapCfg(content []byte, cfg IConfig) error {
 	return nil
 }
 
-func readContent(cfg IConfig) ([]byte, error) {
+// reloadCfg 在副本上完成加载, 校验及初始化, 通过后在写锁内一次性替换正在使用的配置
+func reloadCfg(content []byte, cfg IConfig) error {
+	v := reflect.ValueOf(cfg).Elem()
+	tmp := reflect.New(v.Type()).Interface().(IConfig)
+	err := buildCfg(content, tmp)
+	if utils.HasErr(err) {
+		return err
+	}
+	cfgMu.Lock()
+	v.Set(reflect.ValueOf(tmp).Elem())
+	cfgMu.Unlock()
+	markLoaded(cfg)
+	return nil
+}
+
+func readSource(cfg IConfig) string {
 	s := cfg.Source()
 	if s == SourceDefault {
 		s = source
 	}
-	if s == SourceCenter {
+	return s
+}
+
+func readContent(cfg IConfig) ([]byte, error) {
+	if readSource(cfg) == SourceCenter {
 		return readFromCenter(cfg)
 	}
 	return readFromFile(cfg)
 }
 
+// fileTypes 配置的候选文件类型, 实现IMultiTypeConfig时按声明顺序查找
+func fileTypes(cfg IConfig) []string {
+	if c, ok := cfg.(IMultiTypeConfig); ok {
+		return c.FileTypes()
+	}
+	return []string{cfg.FileType()}
+}
+
+// fileType 本地文件实际命中的类型, 未命中时使用FileType()
+func fileType(cfg IConfig) string {
+	typesMu.RLock()
+	t, ok := resolvedTypes[cfg.Name()]
+	typesMu.RUnlock()
+	if ok {
+		return t
+	}
+	return strings.ToLower(cfg.FileType())
+}
+
+func configFilePath(cfg IConfig) string {
+	return layerFilePath(cfg.Name(), fileType(cfg))
+}
+
+// configFilePaths 基础配置文件及各profile叠加文件
+func configFilePaths(cfg IConfig) []string {
+	var paths []string
+	for _, name := range layerNames(cfg.Name()) {
+		paths = append(paths, layerFilePath(name, fileType(cfg)))
+	}
+	return paths
+}
+
+func layerFilePath(name, t string) string {
+	return AppFilePath(fmt.Sprintf("conf/%s.%s", name, t))
+}
+
 func readFromFile(cfg IConfig) ([]byte, error) {
-	filename := AppFilePath(fmt.Sprintf("conf/%s.%s", cfg.Name(), strings.ToLower(cfg.FileType())))
-	f, err := os.Open(filename)
-	defer f.Close()
-	if utils.HasErr(err) {
-		return nil, err
+	for _, t := range fileTypes(cfg) {
+		t = strings.ToLower(t)
+		if _, err := os.Stat(layerFilePath(cfg.Name(), t)); os.IsNotExist(err) {
+			continue
+		}
+		typesMu.Lock()
+		resolvedTypes[cfg.Name()] = t
+		typesMu.Unlock()
+		return readLayerFiles(cfg)
+	}
+	return nil, fmt.Errorf("conf/%s.{%s} is not found", cfg.Name(), strings.Join(fileTypes(cfg), ","))
+}
+
+// readLayerFiles 读取基础配置并按profile顺序合并存在的叠加文件
+func readLayerFiles(cfg IConfig) ([]byte, error) {
+	t := fileType(cfg)
+	var content []byte
+	for i, filename := range configFilePaths(cfg) {
+		b, err := ioutil.ReadFile(filename)
+		if i > 0 && os.IsNotExist(err) {
+			continue
+		}
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		if i == 0 {
+			content = b
+			continue
+		}
+		content, err = mergeContent(t, content, b)
+		if utils.HasErr(err) {
+			return nil, fmt.Errorf("merge %s err: %+v", filename, err)
+		}
 	}
-	return ioutil.ReadAll(f)
+	return content, nil
 }
 
+// loadContent 加载后仅为内容中未出现的字段设置默认值
 func loadContent(content []byte, cfg IConfig) error {
-	switch cfg.FileType() {
+	switch fileType(cfg) {
 	case TypeJson:
-		return json.Unmarshal(content, cfg)
+		return loadJson(content, cfg)
 	case TypeYaml:
-		return yaml.Unmarshal(content, cfg)
+		return loadYaml(content, cfg)
+	case TypeToml:
+		return loadToml(content, cfg)
+	case TypeEnv:
+		return loadEnv(content, cfg)
 	default:
-		return fmt.Errorf("invalid file type: %s", cfg.FileType())
+		return fmt.Errorf("invalid file type: %s", fileType(cfg))
 	}
 }
 
 func putContent(cfg IConfig) ([]byte, error) {
-	switch cfg.FileType() {
+	switch fileType(cfg) {
 	case TypeJson:
-		return json.Marshal(cfg)
+		return putJson(cfg)
 	case TypeYaml:
-		return yaml.Marshal(cfg)
+		return putYaml(cfg)
+	case TypeToml:
+		return putToml(cfg)
+	case TypeEnv:
+		return putEnv(cfg)
 	default:
-		return nil, fmt.Errorf("invalid file type: %s", cfg.FileType())
+		return nil, fmt.Errorf("invalid file type: %s", fileType(cfg))
+	}
+}
+
+// loadToml 经由json加载, 与json配置共用字段名称
+func loadToml(content []byte, cfg IConfig) error {
+	tree, err := toml.LoadBytes(content)
+	if utils.HasErr(err) {
+		return err
+	}
+	return loadJson(utils.EncodeJsonValue(tree.ToMap()), cfg)
+}
+
+func putToml(cfg IConfig) ([]byte, error) {
+	m, err := jsonMap(cfg)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	tree, err := toml.TreeFromMap(plainNumbers(m).(map[string]interface{}))
+	if utils.HasErr(err) {
+		return nil, err
 	}
+	return []byte(tree.String()), nil
 }
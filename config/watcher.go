@@ -0,0 +1,141 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const watchDebounce = 300 * time.Millisecond
+
+// fileWatcher 监听本地配置文件, 变更经过防抖后重新加载
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	files   map[string]IConfig
+	timers  map[string]*time.Timer
+}
+
+var watcher *fileWatcher
+
+func initWatcher() {
+	if !watch {
+		return
+	}
+	files := make(map[string]IConfig)
+	for _, cfg := range configs {
+		if readSource(cfg) != SourceFile {
+			continue
+		}
+		for _, path := range configFilePaths(cfg) {
+			filename, err := filepath.Abs(path)
+			if utils.HasErr(err) {
+				log.Printf("[WARNING] resolve %s configuration path err: %+v\n", cfg.Name(), err)
+				continue
+			}
+			files[filename] = cfg
+		}
+	}
+	if utils.IsEmpty(files) {
+		return
+	}
+	w, err := fsnotify.NewWatcher()
+	if utils.HasErr(err) {
+		log.Printf("[WARNING] create configuration watcher err: %+v\n", err)
+		return
+	}
+	// 监听目录而非文件, 编辑器通过重命名保存时文件句柄会失效
+	dirs := make(map[string]bool)
+	for filename := range files {
+		dirs[filepath.Dir(filename)] = true
+	}
+	for d := range dirs {
+		if err = w.Add(d); utils.HasErr(err) {
+			log.Printf("[WARNING] watch configuration dir %s err: %+v\n", d, err)
+		}
+	}
+	watcher = &fileWatcher{watcher: w, files: files, timers: make(map[string]*time.Timer)}
+	go watcher.run()
+	log.Println("[INFO] watching local configuration files successfully!")
+}
+
+func stopWatcher() error {
+	if utils.IsEmpty(watcher) {
+		return nil
+	}
+	watcher.mu.Lock()
+	for _, t := range watcher.timers {
+		t.Stop()
+	}
+	watcher.mu.Unlock()
+	return watcher.watcher.Close()
+}
+
+func (w *fileWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			filename, _ := filepath.Abs(event.Name)
+			if cfg, ok := w.files[filename]; ok {
+				w.schedule(filename, cfg)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[ERROR] watch configuration err: %+v\n", err)
+		}
+	}
+}
+
+// schedule 同一文件在防抖间隔内的多次变更只触发一次加载
+func (w *fileWatcher) schedule(filename string, cfg IConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if t, ok := w.timers[filename]; ok {
+		t.Stop()
+	}
+	w.timers[filename] = time.AfterFunc(watchDebounce, func() {
+		w.reload(filename, cfg)
+	})
+}
+
+func (w *fileWatcher) reload(filename string, cfg IConfig) {
+	name := cfg.Name()
+	// 删除profile叠加文件时同样需要重新加载
+	before, err := os.Stat(filename)
+	removed := os.IsNotExist(err)
+	if !removed && (utils.HasErr(err) || before.Size() == 0) {
+		return
+	}
+	content, err := readLayerFiles(cfg)
+	if utils.HasErr(err) {
+		log.Printf("[ERROR] read %s configuration err: %+v\n", name, err)
+		return
+	}
+	// 读取期间文件仍在写入, 等待下一次变更
+	if !removed {
+		after, err := os.Stat(filename)
+		if utils.HasErr(err) || !after.ModTime().Equal(before.ModTime()) || after.Size() != before.Size() {
+			w.schedule(filename, cfg)
+			return
+		}
+	}
+	if err = reloadCfg(content, cfg); utils.HasErr(err) {
+		log.Printf("[ERROR] reload %s configuration err: %+v\n", name, err)
+		return
+	}
+	log.Printf("[INFO] %s configuration reloaded successfully!\n", name)
+}
@@ -0,0 +1,16 @@
+package constants
+
+type Apollo struct {
+	ConfigHost  string            `json:"config_host" binding:"required"`
+	PortalHost  string            `json:"portal_host"`
+	AppId       string            `json:"app_id"`
+	Cluster     string            `json:"cluster"`
+	Env         string            `json:"env"`
+	Secret      string            `json:"secret"`
+	Token       string            `json:"token"`
+	Operator    string            `json:"operator"`
+	Format      string            `json:"format"`
+	Namespaces  map[string]string `json:"namespaces"`
+	TempPath    string            `json:"temp_path"`
+	ServiceName string
+}
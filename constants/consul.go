@@ -0,0 +1,10 @@
+package constants
+
+type Consul struct {
+	Host        string `json:"host" binding:"required"`
+	Token       string `json:"token"`
+	Datacenter  string `json:"datacenter"`
+	Prefix      string `json:"prefix" default:"config"`
+	TTL         int64  `json:"ttl" default:"10"`
+	ServiceName string
+}
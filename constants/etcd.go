@@ -0,0 +1,10 @@
+package constants
+
+type Etcd struct {
+	Hosts       []string `json:"hosts" binding:"required"`
+	Username    string   `json:"username"`
+	Password    string   `json:"password"`
+	Prefix      string   `json:"prefix" default:"/andrews"`
+	TTL         int64    `json:"ttl" default:"10"`
+	ServiceName string
+}
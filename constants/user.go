@@ -1,5 +1,11 @@
 package constants
 
+// UserContextKey 鉴权通过后将*UserBrief存入gin.Context的键
+const UserContextKey = "user"
+
+// ClaimsContextKey 鉴权通过后将*jwt.Claims存入gin.Context的键
+const ClaimsContextKey = "claims"
+
 type UserBrief struct {
 	Uid      int    `json:"uid"`
 	Username string `json:"username"`
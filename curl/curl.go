@@ -2,6 +2,7 @@ package curl
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,12 +10,18 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/EvisuXiao/andrews-common/pkg/trace"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
 const ArrayBodyKey = "rawArray"
 
 func Request(target, method string, data map[string]interface{}, result interface{}, requestWrappers ...func(r *http.Request)) error {
+	return RequestWithContext(context.Background(), target, method, data, result, requestWrappers...)
+}
+
+// RequestWithContext 上下文中存在链路时将X-Request-Id及traceparent传递给下游
+func RequestWithContext(ctx context.Context, target, method string, data map[string]interface{}, result interface{}, requestWrappers ...func(r *http.Request)) (err error) {
 	var body io.Reader
 	if method == http.MethodGet {
 		query := url.Values{}
@@ -33,11 +40,22 @@ func Request(target, method string, data map[string]interface{}, result interfac
 		}
 		body = bytes.NewReader(j)
 	}
-	request, err := http.NewRequest(method, target, body)
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
 	if utils.HasErr(err) {
 		return err
 	}
 	request.Header.Set("Content-Type", "application/json;charset=UTF-8")
+	if trace.FromContext(ctx) != nil {
+		var span *trace.Span
+		ctx, span = trace.StartSpan(ctx, method+" "+request.URL.Host, trace.KindClient)
+		defer span.End()
+		span.SetAttribute("http.method", method)
+		span.SetAttribute("http.url", request.URL.String())
+		trace.Inject(ctx, trace.HeaderCarrier(request.Header))
+		defer func() {
+			span.SetError(err)
+		}()
+	}
 	if !utils.IsEmpty(requestWrappers) {
 		requestWrapper := requestWrappers[0]
 		requestWrapper(request)
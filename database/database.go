@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/driver/mysql"
@@ -11,7 +12,9 @@ import (
 	"gorm.io/plugin/dbresolver"
 
 	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/health"
 	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
@@ -52,16 +55,18 @@ func (db *database) setup() {
 	if utils.IsEmpty(cnf.Master) {
 		logging.Fatal("Init: master database must be valid")
 	}
-	db.db = conn(cnf)
+	db.db = conn(db.name, cnf)
+	health.RegisterChecker("database:"+db.name, db.ping)
 	logging.Info("Database %s setup successfully!", db.name)
 }
 
 /**
  * 数据库连接
+ * @param  string name 数据库标识名
  * @param  *setting.Database cnf DB配置
  * @return *gorm.DB
  */
-func conn(cnf *config.Database) *gorm.DB {
+func conn(name string, cnf *config.Database) *gorm.DB {
 	// 连接主库
 	db, err := gorm.Open(dbDialer(cnf.Driver, cnf.Master), &gorm.Config{
 		NamingStrategy:         schema.NamingStrategy{TablePrefix: cnf.TablePrefix, SingularTable: true},
@@ -85,6 +90,13 @@ func conn(cnf *config.Database) *gorm.DB {
 			logging.Fatal("Init: slave database registered err: %+v", err)
 		}
 	}
+	// 记录操作耗时及连接池状态
+	if err = db.Use(&metricsPlugin{name: name}); utils.HasErr(err) {
+		logging.Fatal("Init: database metrics registered err: %+v", err)
+	}
+	if sqlDB, err := db.DB(); !utils.HasErr(err) {
+		metrics.RegisterDB(name, sqlDB)
+	}
 	// 本地开启调试模式
 	if config.IsLocalEnv() {
 		db = db.Debug()
@@ -188,6 +200,20 @@ func (db *database) GetDb() *gorm.DB {
 	return db.db.Session(&gorm.Session{})
 }
 
+/**
+ * 检查数据库连接
+ * @receiver *Database
+ * @param  context.Context ctx
+ * @return error
+ */
+func (db *database) ping(ctx context.Context) error {
+	sqlDB, err := db.db.DB()
+	if utils.HasErr(err) {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 /**
  * 获取数据库标识名
  * @receiver *Database
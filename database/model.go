@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strconv"
 	"sync"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 
 	"github.com/EvisuXiao/andrews-common/exception"
@@ -21,6 +23,7 @@ type IModel interface {
 type Model struct {
 	*database
 	schema *schema.Schema
+	ctx    context.Context
 	Id     int64 `gorm:"primaryKey" json:"id"`
 }
 
@@ -57,6 +60,31 @@ func (m *Model) SetDatabaseByName(dbName string) {
 	m.database = getDatabaseByName(dbName)
 }
 
+/**
+ * 返回使用指定上下文的模型副本, 上下文取消或超时后未完成的查询随即中止
+ * @receiver *Model
+ * @param  context.Context ctx 上下文, 处理http请求时传入ctx.Request.Context()
+ * @return *Model
+ */
+func (m *Model) WithContext(ctx context.Context) *Model {
+	mm := *m
+	mm.ctx = ctx
+	return &mm
+}
+
+/**
+ * 获取ORM实例, 设置了上下文时附带上下文
+ * @receiver *Model
+ * @return *gorm.DB
+ */
+func (m *Model) GetDb() *gorm.DB {
+	db := m.database.GetDb()
+	if m.ctx != nil {
+		db = db.WithContext(m.ctx)
+	}
+	return db
+}
+
 /**
  * 获取数据表模型属性
  * @receiver *Model
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"time"
 )
 
@@ -9,3 +10,15 @@ type ModelInsertable struct {
 	Model
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
+
+/**
+ * 返回使用指定上下文的模型副本
+ * @receiver *ModelInsertable
+ * @param  context.Context ctx 上下文
+ * @return *ModelInsertable
+ */
+func (m *ModelInsertable) WithContext(ctx context.Context) *ModelInsertable {
+	mm := *m
+	mm.ctx = ctx
+	return &mm
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"time"
 
 	"github.com/EvisuXiao/andrews-common/utils"
@@ -14,6 +15,18 @@ type ModelUpdatable struct {
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+/**
+ * 返回使用指定上下文的模型副本
+ * @receiver *ModelUpdatable
+ * @param  context.Context ctx 上下文
+ * @return *ModelUpdatable
+ */
+func (m *ModelUpdatable) WithContext(ctx context.Context) *ModelUpdatable {
+	mm := *m
+	mm.ctx = ctx
+	return &mm
+}
+
 /**
  * 获取更新时间键名
  * @receiver *ModelUpdatable
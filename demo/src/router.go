@@ -11,10 +11,9 @@ import (
 func InitRouter() *cHttp.MainRouterGroup {
 	groups := []*cHttp.RouterGroup{
 		{
-			"",
-			[]cHttp.RouterHandler{},
-			[]*cHttp.RouterItem{
-				{http.MethodGet, "test", (&routerTest{}).test1},
+			Middleware: []cHttp.RouterHandler{},
+			Routers: []*cHttp.RouterItem{
+				{Method: http.MethodGet, Path: "test", Handlers: (&routerTest{}).test1, Summary: "test", Response: ""},
 			},
 		},
 	}
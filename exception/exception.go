@@ -17,6 +17,9 @@ var (
 	INVALID_PARAM_ERR = CustomErrWrapper(INVALID_PARAM_MSG)
 	DB_ERROR_ERR      = CustomErrWrapper(DB_ERROR_MSG)
 	SERVER_ERROR_ERR  = CustomErrWrapper(SERVER_ERROR_MSG)
+	UNAUTHORIZED_ERR  = CustomErrWrapper(UNAUTHORIZED_MSG)
+	FORBIDDEN_ERR     = CustomErrWrapper(FORBIDDEN_MSG)
+	TIMEOUT_ERR       = CustomErrWrapper(TIMEOUT_MSG)
 )
 
 func DbErrWrapper(err error) *DbError {
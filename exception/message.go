@@ -6,10 +6,16 @@ const (
 	SUCCESS_CODE = http.StatusOK
 	FAILURE_CODE = http.StatusInternalServerError
 	PARAM_CODE   = http.StatusBadRequest
+	AUTH_CODE    = http.StatusUnauthorized
+	FORBID_CODE  = http.StatusForbidden
+	TIMEOUT_CODE = http.StatusGatewayTimeout
 
 	SUCCESS_MSG       = "操作成功"
 	FAILURE_MSG       = "操作失败"
 	INVALID_PARAM_MSG = "请求参数有误"
 	DB_ERROR_MSG      = "数据库操作异常"
 	SERVER_ERROR_MSG  = "系统内部异常"
+	UNAUTHORIZED_MSG  = "登录已失效, 请重新登录"
+	FORBIDDEN_MSG     = "无访问权限"
+	TIMEOUT_MSG       = "请求处理超时"
 )
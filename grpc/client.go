@@ -0,0 +1,19 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/EvisuXiao/andrews-common/config"
+)
+
+// Dial 创建客户端连接, 默认附带链路传递拦截器, 开启指标时记录调用
+func Dial(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	dialOpts := []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientTraceInterceptor()),
+		grpc.WithChainStreamInterceptor(StreamClientTraceInterceptor()),
+	}
+	if config.GetServerConfig().Metrics.Enabled {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(UnaryClientMetricsInterceptor()))
+	}
+	return grpc.Dial(target, append(dialOpts, opts...)...)
+}
@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/EvisuXiao/andrews-common/pkg/trace"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// tracedClientStream 流结束时才结束span, 接收到io.EOF或错误、单响应流收到响应、CloseSend失败或流上下文结束
+type tracedClientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	span *trace.Span
+}
+
+// UnaryServerTraceInterceptor 从metadata读取或生成x-request-id及traceparent并写入上下文
+func UnaryServerTraceInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+		resp, err := handler(ctx, req)
+		span.SetError(err)
+		return resp, err
+	}
+}
+
+func StreamServerTraceInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		span.SetError(err)
+		return err
+	}
+}
+
+// UnaryClientTraceInterceptor 上下文中存在链路时写入metadata传递给下游
+func UnaryClientTraceInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if trace.FromContext(ctx) == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, span := startClientSpan(ctx, method)
+		defer span.End()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		span.SetError(err)
+		return err
+	}
+}
+
+func StreamClientTraceInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if trace.FromContext(ctx) == nil {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		ctx, span := startClientSpan(ctx, method)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if utils.HasErr(err) {
+			span.SetError(err)
+			span.End()
+			return cs, err
+		}
+		go func() {
+			<-cs.Context().Done()
+			span.End()
+		}()
+		return &tracedClientStream{ClientStream: cs, desc: desc, span: span}, nil
+	}
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.span.End()
+	case utils.HasErr(err):
+		s.span.SetError(err)
+		s.span.End()
+	case !s.desc.ServerStreams:
+		s.span.End()
+	}
+	return err
+}
+
+func (s *tracedClientStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if utils.HasErr(err) {
+		s.span.SetError(err)
+		s.span.End()
+	}
+	return err
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func startServerSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	remote := trace.Extract(trace.MetadataCarrier(md))
+	ctx, span := trace.StartSpan(trace.NewContext(ctx, remote), method, trace.KindServer)
+	span.SetAttribute("rpc.method", method)
+	return ctx, span
+}
+
+func startClientSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, method, trace.KindClient)
+	span.SetAttribute("rpc.method", method)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	trace.Inject(ctx, trace.MetadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/EvisuXiao/andrews-common/metrics"
+)
+
+// UnaryServerMetricsInterceptor 按方法及状态码记录调用次数及耗时
+func UnaryServerMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeServer(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamServerMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeServer(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func UnaryClientMetricsInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		code := status.Code(err).String()
+		metrics.GrpcClientHandled.WithLabelValues(method, code).Inc()
+		metrics.GrpcClientDuration.WithLabelValues(method, code).Observe(metrics.Since(start))
+		return err
+	}
+}
+
+func observeServer(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metrics.GrpcServerHandled.WithLabelValues(method, code).Inc()
+	metrics.GrpcServerDuration.WithLabelValues(method, code).Observe(metrics.Since(start))
+}
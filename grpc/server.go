@@ -8,6 +8,7 @@ import (
 
 	"github.com/EvisuXiao/andrews-common/config"
 	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
 	"github.com/EvisuXiao/andrews-common/server"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
@@ -20,16 +21,34 @@ type Server struct {
 
 type Option struct {
 	server.Option
+	ServerOptions []grpc.ServerOption
+	Register      func(s *grpc.Server)
 }
 
 func StartServer(option *Option) {
-	port := fmt.Sprintf(":%d", option.Config.Port)
-	listener, err := net.Listen("tcp", port)
+	port := option.Config.Port
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if utils.HasErr(err) {
 		logging.Fatal("Listen tcp port(%d) err: %+v", port, err)
 	}
-	s := &Server{srv: grpc.NewServer(), listener: listener, option: option}
-	s.srv = grpc.NewServer()
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerTraceInterceptor()),
+		grpc.ChainStreamInterceptor(StreamServerTraceInterceptor()),
+	}
+	if cfg := option.Config.Metrics; cfg.Enabled {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(UnaryServerMetricsInterceptor()),
+			grpc.ChainStreamInterceptor(StreamServerMetricsInterceptor()),
+		)
+		if cfg.Port > 0 {
+			metrics.StartServer(cfg.Port, cfg.Path)
+		}
+	}
+	opts = append(opts, option.ServerOptions...)
+	s := &Server{srv: grpc.NewServer(opts...), listener: listener, option: option}
+	if !utils.IsEmpty(option.Register) {
+		option.Register(s.srv)
+	}
 	server.StartServer(s)
 }
 
@@ -66,3 +85,15 @@ func (o *Option) WithQuitHandler(handler func()) *Option {
 	o.Option.WithQuitHandler(handler)
 	return o
 }
+
+// WithServerOptions 追加grpc服务选项, 如自定义拦截器
+func (o *Option) WithServerOptions(opts ...grpc.ServerOption) *Option {
+	o.ServerOptions = append(o.ServerOptions, opts...)
+	return o
+}
+
+// WithRegister 注册grpc服务
+func (o *Option) WithRegister(register func(s *grpc.Server)) *Option {
+	o.Register = register
+	return o
+}
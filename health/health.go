@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DefaultTimeout 单个检查的超时时间
+const DefaultTimeout = 3 * time.Second
+
+// Checker 依赖检查, 返回错误视为不可用
+type Checker func(ctx context.Context) error
+
+type Result struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency,omitempty"`
+}
+
+type Report struct {
+	Status string    `json:"status"`
+	Checks []*Result `json:"checks"`
+}
+
+var (
+	checkers   = make(map[string]Checker)
+	checkersMu sync.RWMutex
+	ready      = true
+	readyMu    sync.RWMutex
+
+	ErrShuttingDown = errors.New("server is shutting down")
+)
+
+// RegisterChecker 注册就绪检查, 同名覆盖
+func RegisterChecker(name string, checker Checker) {
+	checkersMu.Lock()
+	checkers[name] = checker
+	checkersMu.Unlock()
+}
+
+func UnregisterChecker(name string) {
+	checkersMu.Lock()
+	delete(checkers, name)
+	checkersMu.Unlock()
+}
+
+// SetReady 服务开始停止时置为false, 使负载均衡在退出前摘除流量
+func SetReady(r bool) {
+	readyMu.Lock()
+	ready = r
+	readyMu.Unlock()
+}
+
+func IsReady() bool {
+	readyMu.RLock()
+	defer readyMu.RUnlock()
+	return ready
+}
+
+// Readiness 并发执行全部检查, 服务停止中或任一检查失败时为down
+func Readiness(ctx context.Context) *Report {
+	report := &Report{Status: StatusUp}
+	if !IsReady() {
+		report.Status = StatusDown
+		report.Checks = append(report.Checks, &Result{Name: "server", Status: StatusDown, Error: ErrShuttingDown.Error()})
+		return report
+	}
+	checkersMu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]*Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string, checker Checker) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, name, checker)
+		}(i, name, checkers[name])
+	}
+	checkersMu.RUnlock()
+	wg.Wait()
+	for _, r := range results {
+		if r.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	report.Checks = results
+	return report
+}
+
+func runCheck(ctx context.Context, name string, checker Checker) *Result {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	start := time.Now()
+	result := &Result{Name: name, Status: StatusUp}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("checker panic: %v", r)
+			}
+		}()
+		done <- checker(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result.Latency = time.Since(start).String()
+	if utils.HasErr(err) {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/exception"
+)
+
+type adminController struct {
+	Controller
+}
+
+// NewAdminRouterGroup 管理接口路由, 可直接传入InitRouter, 生产环境请传入鉴权中间件
+// GET <path>/config 查看当前生效的配置, ?name=<name> 查看单个配置
+// GET <path>/routes 查看已注册的路由及其元数据
+func NewAdminRouterGroup(path string, middleware ...RouterHandler) *MainRouterGroup {
+	admin := &adminController{}
+	return &MainRouterGroup{
+		Path:       path,
+		Middleware: middleware,
+		Groups: []*RouterGroup{
+			{
+				Routers: []*RouterItem{
+					{Method: http.MethodGet, Path: "config", Handlers: admin.config},
+					{Method: http.MethodGet, Path: "routes", Handlers: admin.routes},
+				},
+			},
+		},
+	}
+}
+
+func (a *adminController) config(ctx *gin.Context) bool {
+	name := ctx.Query("name")
+	if name == "" {
+		return a.SuccessResponse(ctx, config.GetStates())
+	}
+	for _, state := range config.GetStates() {
+		if state.Name == name {
+			return a.SuccessResponse(ctx, state)
+		}
+	}
+	return a.FailureResponseWithCode(ctx, http.StatusNotFound, exception.CustomErrWrapper("configuration %s is not found", name))
+}
+
+func (a *adminController) routes(ctx *gin.Context) bool {
+	return a.SuccessResponse(ctx, GetRoutes())
+}
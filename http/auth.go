@@ -0,0 +1,228 @@
+package http
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/jwt"
+	"github.com/EvisuXiao/andrews-common/pkg/redis"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const bearerPrefix = "Bearer "
+
+var (
+	authManager *jwt.Manager
+	authOnce    sync.Once
+)
+
+// GetAuthManager 按server.auth配置创建令牌管理器, 未配置secret时返回nil, 登录接口使用其签发令牌
+func GetAuthManager() *jwt.Manager {
+	authOnce.Do(func() {
+		if authManager != nil {
+			return
+		}
+		cfg := config.GetServerConfig().Auth
+		if utils.IsEmpty(cfg.Secret) && utils.IsEmpty(cfg.Keys) && utils.IsEmpty(cfg.JwksUrl) {
+			return
+		}
+		opt := &jwt.Option{
+			Secret:         cfg.Secret,
+			Issuer:         cfg.Issuer,
+			Audience:       cfg.Audience,
+			Leeway:         cfg.Leeway,
+			AccessExpired:  cfg.AccessExpired,
+			RefreshExpired: cfg.RefreshExpired,
+		}
+		if !utils.IsEmpty(cfg.Keys) {
+			opt.Keys = loadAuthKeys(&cfg)
+		}
+		if !utils.IsEmpty(cfg.JwksUrl) {
+			opt.Resolver = jwt.NewJwksClient(cfg.JwksUrl, cfg.JwksCacheTtl)
+		}
+		if !utils.IsEmpty(cfg.Redis) {
+			client := redis.GetRedis(cfg.Redis)
+			if client == nil {
+				logging.Fatal("Init auth fatal: redis(%s) connection is not registered", cfg.Redis)
+			}
+			opt.Revoker = jwt.NewRedisRevoker(client)
+		}
+		var err error
+		if authManager, err = jwt.NewManager(opt); utils.HasErr(err) {
+			logging.Fatal("Init auth fatal: %+v", err)
+		}
+	})
+	return authManager
+}
+
+func loadAuthKeys(cfg *config.Auth) *jwt.KeySet {
+	keys := jwt.NewKeySet()
+	for _, k := range cfg.Keys {
+		path := k.Path
+		if !filepath.IsAbs(path) {
+			path = config.AppFilePath(path)
+		}
+		key, err := jwt.LoadKey(k.Id, k.Algorithm, path)
+		if utils.HasErr(err) {
+			logging.Fatal("Init auth fatal: load key %s err: %+v", k.Id, err)
+		}
+		keys.Add(key)
+	}
+	if !utils.IsEmpty(cfg.SigningKey) {
+		if err := keys.SetSigningKey(cfg.SigningKey); utils.HasErr(err) {
+			logging.Fatal("Init auth fatal: signing key %s err: %+v", cfg.SigningKey, err)
+		}
+	}
+	return keys
+}
+
+// SetAuthManager 使用自定义的令牌管理器替代配置, 需在InitRouter之前调用
+func SetAuthManager(manager *jwt.Manager) {
+	authManager = manager
+}
+
+// Auth 校验Bearer或cookie中的访问令牌, 通过后将*UserBrief存入上下文, 失败时返回401
+func (m *Middleware) Auth(manager *jwt.Manager, cookie string) RouterHandler {
+	return func(c *gin.Context) bool {
+		token := GetToken(c, cookie)
+		if utils.IsEmpty(token) {
+			return m.UnauthorizedResponse(c)
+		}
+		claims, err := manager.ParseAccess(c.Request.Context(), token)
+		if utils.HasErr(err) {
+			if !jwt.IsTokenError(err) {
+				logging.Ctx(c).Error("Verify token err: %+v", err)
+				return m.UnauthorizedResponse(c)
+			}
+			return m.UnauthorizedResponse(c, err)
+		}
+		user := claims.UserBrief
+		c.Set(constants.UserContextKey, &user)
+		c.Set(constants.ClaimsContextKey, claims)
+		return m.Next(c)
+	}
+}
+
+// UnauthorizedResponse 以401应答, 传入令牌错误时在data中返回原因, 便于客户端判断是否刷新令牌
+func (c *Controller) UnauthorizedResponse(ctx *gin.Context, reason ...error) bool {
+	if !utils.IsEmpty(reason) && reason[0] != nil {
+		return c.FailureResponseWithCode(ctx, exception.AUTH_CODE, exception.UNAUTHORIZED_ERR, reason[0].Error())
+	}
+	return c.FailureResponseWithCode(ctx, exception.AUTH_CODE, exception.UNAUTHORIZED_ERR)
+}
+
+// GetToken 优先读取Authorization: Bearer, 其次读取指定cookie
+func GetToken(c *gin.Context, cookie string) string {
+	if header := c.GetHeader("Authorization"); len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	if utils.IsEmpty(cookie) {
+		return ""
+	}
+	token, _ := c.Cookie(cookie)
+	return token
+}
+
+// GetClaims 获取鉴权通过的令牌声明, 自定义声明使用Claims.Bind读取, 未鉴权时返回nil
+func GetClaims(c *gin.Context) *jwt.Claims {
+	v, ok := c.Get(constants.ClaimsContextKey)
+	if !ok {
+		return nil
+	}
+	claims, _ := v.(*jwt.Claims)
+	return claims
+}
+
+// GetUser 获取鉴权通过的用户, 未鉴权时返回nil
+func GetUser(c *gin.Context) *constants.UserBrief {
+	v, ok := c.Get(constants.UserContextKey)
+	if !ok {
+		return nil
+	}
+	switch user := v.(type) {
+	case *constants.UserBrief:
+		return user
+	case constants.UserBrief:
+		return &user
+	}
+	return nil
+}
+
+type authController struct {
+	Controller
+	manager *jwt.Manager
+	cookie  string
+}
+
+type refreshTokenReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewAuthRouterGroup 令牌接口路由, 使用server.auth配置的令牌管理器
+// POST <path>/refresh 使用refresh_token换取新的令牌对, 旧的刷新令牌随即失效
+// POST <path>/logout 吊销当前访问令牌及请求体中的refresh_token
+// GET <path>/jwks 配置非对称密钥时返回公钥, 供其他服务设置为jwks_url
+func NewAuthRouterGroup(path string) *MainRouterGroup {
+	manager := GetAuthManager()
+	if manager == nil {
+		logging.Fatal("Init auth fatal: server.auth.secret is not configured")
+	}
+	auth := &authController{manager: manager, cookie: config.GetServerConfig().Auth.Cookie}
+	routers := []*RouterItem{
+		{Method: http.MethodPost, Path: "refresh", Handlers: auth.refresh, Summary: "刷新令牌", Tags: []string{"auth"}, Request: refreshTokenReq{}, Response: jwt.TokenPair{}},
+		{Method: http.MethodPost, Path: "logout", Handlers: auth.logout, Summary: "注销", Tags: []string{"auth"}, Request: refreshTokenReq{}},
+	}
+	if manager.Keys() != nil {
+		routers = append(routers, &RouterItem{Method: http.MethodGet, Path: "jwks", Handlers: JwksHandler(manager.Keys())})
+	}
+	return &MainRouterGroup{
+		Path:   path,
+		Groups: []*RouterGroup{{Routers: routers}},
+	}
+}
+
+// JwksHandler 按RFC 7517格式直接返回公钥集合, 不使用ApiOutput包装
+func JwksHandler(keys *jwt.KeySet) RouterHandler {
+	return func(ctx *gin.Context) bool {
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.AbortWithStatusJSON(http.StatusOK, keys.JWKS())
+		return true
+	}
+}
+
+func (a *authController) refresh(ctx *gin.Context) bool {
+	req := &refreshTokenReq{}
+	if err := ctx.ShouldBindJSON(req); utils.HasErr(err) || utils.IsEmpty(req.RefreshToken) {
+		return a.UnauthorizedResponse(ctx)
+	}
+	pair, err := a.manager.Refresh(ctx.Request.Context(), req.RefreshToken)
+	if utils.HasErr(err) {
+		if !jwt.IsTokenError(err) {
+			logging.Ctx(ctx).Error("Refresh token err: %+v", err)
+			return a.UnauthorizedResponse(ctx)
+		}
+		return a.UnauthorizedResponse(ctx, err)
+	}
+	return a.SuccessResponse(ctx, pair)
+}
+
+func (a *authController) logout(ctx *gin.Context) bool {
+	req := &refreshTokenReq{}
+	_ = ctx.ShouldBindJSON(req)
+	err := a.manager.Revoke(ctx.Request.Context(), GetToken(ctx, a.cookie), req.RefreshToken)
+	if utils.HasErr(err) && !jwt.IsTokenError(err) {
+		return a.FailureResponse(ctx, err)
+	}
+	if !utils.IsEmpty(a.cookie) {
+		ctx.SetCookie(a.cookie, "", -1, "/", "", false, true)
+	}
+	return a.SuccessResponse(ctx)
+}
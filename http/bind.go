@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/EvisuXiao/andrews-common/exception"
+	cValidator "github.com/EvisuXiao/andrews-common/pkg/validator"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// Bind 依次合并header, query, body(json/form等)及路径参数到req, 后者覆盖前者, 再统一校验
+// 失败时已响应参数错误, 调用方直接返回true即可
+func (c *Controller) Bind(ctx *gin.Context, req interface{}) bool {
+	binders := []func(interface{}) error{ctx.ShouldBindHeader, ctx.ShouldBindQuery}
+	if hasBody(ctx.Request) {
+		binders = append(binders, func(obj interface{}) error {
+			return ctx.ShouldBindWith(obj, binding.Default(ctx.Request.Method, ctx.ContentType()))
+		})
+	}
+	if !utils.IsEmpty(ctx.Params) {
+		binders = append(binders, ctx.ShouldBindUri)
+	}
+	for _, bind := range binders {
+		// 单个来源的数据不完整, 校验错误留待合并后统一处理
+		if err := bind(req); utils.HasErr(err) && !isValidationErr(err) {
+			c.InvalidParamResponse(ctx, err)
+			return false
+		}
+	}
+	if fields := cValidator.CheckRequest(req); !utils.IsEmpty(fields) {
+		c.InvalidFieldsResponse(ctx, fields)
+		return false
+	}
+	return true
+}
+
+// InvalidFieldsResponse 按字段返回全部参数错误, 键为请求中的字段路径
+func (c *Controller) InvalidFieldsResponse(ctx *gin.Context, fields map[string]string) bool {
+	data := make(map[string]string, len(fields))
+	for k, v := range fields {
+		data[trimRootNamespace(k)] = v
+	}
+	output := NewOutput(exception.PARAM_CODE)
+	output.SetMessage(exception.INVALID_PARAM_MSG)
+	output.SetData(data)
+	return output.ApiResponse(ctx)
+}
+
+func hasBody(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return false
+	}
+	return r.ContentLength > 0 || r.ContentLength == -1
+}
+
+// isValidationErr 结构体或切片中各元素的校验错误
+func isValidationErr(err error) bool {
+	if _, ok := err.(validator.ValidationErrors); ok {
+		return true
+	}
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Slice {
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		e, ok := v.Index(i).Interface().(error)
+		if !ok || !isValidationErr(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// trimRootNamespace 去掉字段路径中的结构体名称, 如LoginReq.user.name为user.name
+func trimRootNamespace(namespace string) string {
+	if strings.HasPrefix(namespace, "[") {
+		return namespace
+	}
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/openapi"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// apiOutputComponent 文档中统一响应结构的组件名称, 各接口的响应类型作为其data字段
+const apiOutputComponent = "ApiOutput"
+
+const swaggerUiHtml = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>window.ui = SwaggerUIBundle({url: "{{.Url}}", dom_id: "#swagger-ui"});</script>
+</body>
+</html>`
+
+// initDocs 开启文档时生成OpenAPI文档并注册Swagger UI, 不经过全局中间件
+func initDocs(r *gin.Engine, groups []*MainRouterGroup) {
+	cfg := config.GetServerConfig().Docs
+	if !cfg.Enabled {
+		return
+	}
+	doc, err := json.Marshal(NewOpenApi(groups...))
+	if utils.HasErr(err) {
+		logging.Fatal("Init docs fatal: marshal openapi document err: %+v", err)
+	}
+	docPath := joinPath(cfg.Path, "openapi.json")
+	page := &strings.Builder{}
+	err = template.Must(template.New("swagger").Parse(swaggerUiHtml)).Execute(page, map[string]string{
+		"Title": docsTitle(&cfg),
+		"Url":   docPath,
+	})
+	if utils.HasErr(err) {
+		logging.Fatal("Init docs fatal: render swagger ui err: %+v", err)
+	}
+	r.GET(docPath, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json; charset=utf-8", doc)
+	})
+	r.GET(cfg.Path, func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page.String()))
+	})
+}
+
+// NewOpenApi 按路由声明生成OpenAPI文档, 响应全部方法的路由不生成
+func NewOpenApi(groups ...*MainRouterGroup) *openapi.Document {
+	cfg := config.GetServerConfig().Docs
+	g := openapi.NewGenerator(openapi.Info{Title: docsTitle(&cfg), Description: cfg.Description, Version: cfg.Version})
+	g.AddComponent(apiOutputComponent, ApiOutput{})
+	for _, main := range groups {
+		addOperations(g, joinPath("/", main.Path), main.Groups)
+	}
+	return g.Document()
+}
+
+func addOperations(g *openapi.Generator, base string, groups []*RouterGroup) {
+	for _, group := range groups {
+		groupPath := joinPath(base, group.Path)
+		for _, router := range group.Routers {
+			method := strings.ToUpper(router.Method)
+			if utils.IsEmpty(method) || method == MethodAny {
+				continue
+			}
+			g.AddOperation(&openapi.OperationSpec{
+				Method:      method,
+				Path:        joinPath(groupPath, router.Path),
+				Summary:     router.Summary,
+				Description: routerDescription(router),
+				Tags:        router.Tags,
+				Request:     router.Request,
+				Response:    router.Response,
+				Envelope:    apiOutputComponent,
+			})
+		}
+		addOperations(g, groupPath, group.Groups)
+	}
+}
+
+func docsTitle(cfg *config.Docs) string {
+	if utils.IsEmpty(cfg.Title) {
+		return config.GetServiceName()
+	}
+	return cfg.Title
+}
+
+// routerDescription 在说明中列出访问所需权限
+func routerDescription(router *RouterItem) string {
+	if utils.IsEmpty(router.Permission) {
+		return ""
+	}
+	return fmt.Sprintf("permission: %s", strings.Join(router.Permission, ", "))
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/health"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+var healthCtl = &healthController{}
+
+type healthController struct {
+	Controller
+}
+
+// liveness 进程存活即返回成功
+func (h *healthController) liveness(ctx *gin.Context) bool {
+	return h.SuccessResponse(ctx, gin.H{"status": health.StatusUp})
+}
+
+// readiness 依赖检查全部通过时返回成功, 否则以503返回各项检查结果
+func (h *healthController) readiness(ctx *gin.Context) bool {
+	report := health.Readiness(ctx.Request.Context())
+	if report.Status == health.StatusUp {
+		return h.SuccessResponse(ctx, report)
+	}
+	output := NewOutput(http.StatusServiceUnavailable)
+	output.SetMessage("service is not ready")
+	output.SetData(report)
+	ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, output)
+	return true
+}
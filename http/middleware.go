@@ -1,13 +1,27 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/juju/ratelimit"
 
+	"github.com/EvisuXiao/andrews-common/config"
 	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
+	"github.com/EvisuXiao/andrews-common/pkg/trace"
+	"github.com/EvisuXiao/andrews-common/utils"
 )
 
 var middleware = &Middleware{}
@@ -25,3 +39,148 @@ func (m *Middleware) RateLimiter(limit int) RouterHandler {
 		return m.Next(c)
 	}
 }
+
+// Trace 读取或生成X-Request-Id及traceparent, 写入请求上下文及gin.Context, 并在响应头返回请求id
+func (m *Middleware) Trace() RouterHandler {
+	return func(c *gin.Context) bool {
+		remote := trace.Extract(trace.HeaderCarrier(c.Request.Header))
+		name := c.FullPath()
+		if utils.IsEmpty(name) {
+			name = c.Request.URL.Path
+		}
+		ctx, span := trace.StartSpan(trace.NewContext(c.Request.Context(), remote), c.Request.Method+" "+name, trace.KindServer)
+		defer span.End()
+		sc := trace.FromContext(ctx)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(trace.ContextKey, sc)
+		c.Header(trace.HeaderRequestId, sc.RequestId)
+		m.Next(c)
+		status := c.Writer.Status()
+		span.SetAttribute("http.method", c.Request.Method)
+		span.SetAttribute("http.route", name)
+		span.SetAttribute("http.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.SetError(fmt.Errorf("response with status %d", status))
+		}
+		return true
+	}
+}
+
+// Metrics 按路由及状态码记录请求数及耗时, 未匹配的路由统一记为unmatched
+func (m *Middleware) Metrics() RouterHandler {
+	return func(c *gin.Context) bool {
+		start := time.Now()
+		m.Next(c)
+		route := c.FullPath()
+		if utils.IsEmpty(route) {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HttpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HttpDuration.WithLabelValues(c.Request.Method, route, status).Observe(metrics.Since(start))
+		return true
+	}
+}
+
+// AccessLog 通过logging记录访问日志, 包含请求方法, 路径, 状态码, 耗时, 客户端IP, 请求id, 用户id及响应大小
+func (m *Middleware) AccessLog(cfg *config.AccessLog) RouterHandler {
+	return func(c *gin.Context) bool {
+		path := c.Request.URL.Path
+		if cfg.Disabled || isExcludedPath(cfg.Exclude, path) {
+			return m.Next(c)
+		}
+		start := time.Now()
+		m.Next(c)
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError && rand.Float64() >= cfg.SampleRate {
+			return true
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		fields := []logging.Field{
+			logging.F("method", c.Request.Method),
+			logging.F("path", path),
+			logging.F("status", status),
+			logging.F("latency", time.Since(start)),
+			logging.F("ip", c.ClientIP()),
+			logging.F("size", size),
+		}
+		if query := c.Request.URL.RawQuery; !utils.IsEmpty(query) {
+			fields = append(fields, logging.F("query", query))
+		}
+		if logging.ContextValue(c, logging.RequestIdKey) == nil && !utils.IsEmpty(c.GetHeader(trace.HeaderRequestId)) {
+			fields = append(fields, logging.F(logging.RequestIdKey, c.GetHeader(trace.HeaderRequestId)))
+		}
+		if user := GetUser(c); user != nil {
+			fields = append(fields, logging.F("user_id", user.Uid))
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); !utils.IsEmpty(errs) {
+			fields = append(fields, logging.F("error", strings.TrimSpace(errs)))
+		}
+		level := logging.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = logging.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = logging.LevelWarning
+		}
+		logging.Ctx(c).With(fields...).Print(level, "%s %s %d", c.Request.Method, path, status)
+		return true
+	}
+}
+
+// Recovery 捕获panic并记录堆栈, 以ApiOutput返回系统内部异常
+func (m *Middleware) Recovery() RouterHandler {
+	return func(c *gin.Context) bool {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			l := logging.Ctx(c).With(
+				logging.F("method", c.Request.Method),
+				logging.F("path", c.Request.URL.Path),
+			)
+			if isBrokenPipe(err) {
+				l.Error("connection is broken: %v", err)
+				_ = c.Error(err.(error))
+				c.Abort()
+				return
+			}
+			l.With(logging.F("stack", string(debug.Stack()))).Error("panic recovered: %v", err)
+			m.FailureResponse(c, exception.SERVER_ERROR_ERR)
+		}()
+		return m.Next(c)
+	}
+}
+
+func isExcludedPath(exclude []string, path string) bool {
+	for _, p := range exclude {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isBrokenPipe 客户端已断开连接时无法再写入响应
+func isBrokenPipe(err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	var ne *net.OpError
+	if !errors.As(e, &ne) {
+		return false
+	}
+	var se *os.SyscallError
+	if errors.As(ne, &se) {
+		return errors.Is(se.Err, syscall.EPIPE) || errors.Is(se.Err, syscall.ECONNRESET)
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/ratelimit"
+	"github.com/EvisuXiao/andrews-common/pkg/redis"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// 内置限流键
+const (
+	RateLimitKeyIp     = "ip"
+	RateLimitKeyUser   = "user"
+	RateLimitKeyApiKey = "api_key"
+)
+
+// RateLimitKeyFunc 返回限流键, 返回空字符串时不限流
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitPolicy 分布式限流策略, Name区分不同策略的计数
+type RateLimitPolicy struct {
+	Name    string
+	Limiter *ratelimit.Limiter
+	Limit   ratelimit.Limit
+	KeyFunc RateLimitKeyFunc
+}
+
+var (
+	rateLimitKeys   = make(map[string]RateLimitKeyFunc)
+	rateLimitKeysMu sync.RWMutex
+)
+
+func init() {
+	RegisterRateLimitKey(RateLimitKeyIp, KeyByIp)
+	RegisterRateLimitKey(RateLimitKeyUser, KeyByUser)
+}
+
+// RegisterRateLimitKey 注册自定义限流键, 可在server.rate_limits的key中按名称使用
+func RegisterRateLimitKey(name string, fn RateLimitKeyFunc) {
+	rateLimitKeysMu.Lock()
+	rateLimitKeys[name] = fn
+	rateLimitKeysMu.Unlock()
+}
+
+// trustProxyHeaders 配置了server.trusted_proxies时为true
+var trustProxyHeaders bool
+
+// KeyByIp 按客户端ip限流, 配置server.trusted_proxies时使用可信代理转发的ClientIP,
+// 否则使用连接的对端地址, 不读取X-Forwarded-For, 避免客户端伪造请求头获得新的限额
+func KeyByIp(c *gin.Context) string {
+	if trustProxyHeaders {
+		return "ip:" + c.ClientIP()
+	}
+	if ip, _ := c.RemoteIP(); ip != nil {
+		return "ip:" + ip.String()
+	}
+	return "ip:" + c.Request.RemoteAddr
+}
+
+// KeyByUser 未登录时按ip限流
+func KeyByUser(c *gin.Context) string {
+	if user := GetUser(c); user != nil {
+		return "user:" + strconv.Itoa(user.Uid)
+	}
+	return KeyByIp(c)
+}
+
+// KeyByHeader 按请求头限流, 如API key, 请求头为空时按ip限流
+func KeyByHeader(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if v := c.GetHeader(header); !utils.IsEmpty(v) {
+			return "header:" + utils.EncodeMd5Str(v)
+		}
+		return KeyByIp(c)
+	}
+}
+
+// DistributedRateLimiter 基于redis的限流, 各副本共享限额, 响应携带X-RateLimit-*头, 被拒绝时携带Retry-After
+// redis不可用时放行并记录日志
+func (m *Middleware) DistributedRateLimiter(policy *RateLimitPolicy) RouterHandler {
+	limit := strconv.Itoa(policy.Limit.Rate)
+	return func(c *gin.Context) bool {
+		key := policy.KeyFunc(c)
+		if utils.IsEmpty(key) {
+			return m.Next(c)
+		}
+		res, err := policy.Limiter.Allow(c.Request.Context(), policy.Name+":"+key, policy.Limit)
+		if utils.HasErr(err) {
+			logging.Ctx(c).Warning("Rate limit %s err: %+v", policy.Name, err)
+			return m.Next(c)
+		}
+		c.Header("X-RateLimit-Limit", limit)
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(res.ResetAfter.Seconds()))))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			return m.FailureResponseWithCode(c, http.StatusTooManyRequests, exception.CustomErrWrapper("too many requests"))
+		}
+		return m.Next(c)
+	}
+}
+
+// registerRateLimitPolicies 将server.rate_limits中的策略注册为 rate_limit:<策略名> 中间件
+func registerRateLimitPolicies() {
+	for name, cfg := range config.GetServerConfig().RateLimits {
+		name, cfg := name, cfg
+		RegisterMiddleware(MiddlewareRateLimit+":"+name, func() RouterHandler {
+			return middleware.DistributedRateLimiter(newRateLimitPolicy(name, cfg))
+		})
+	}
+}
+
+func newRateLimitPolicy(name string, cfg *config.RateLimitPolicy) *RateLimitPolicy {
+	client := redis.GetRedis(cfg.Redis)
+	if client == nil {
+		logging.Fatal("Init rate limit fatal: redis(%s) connection of policy %s is not registered", cfg.Redis, name)
+	}
+	var keyFunc RateLimitKeyFunc
+	if strings.EqualFold(cfg.Key, RateLimitKeyApiKey) {
+		keyFunc = KeyByHeader(cfg.Header)
+	} else {
+		rateLimitKeysMu.RLock()
+		keyFunc = rateLimitKeys[cfg.Key]
+		rateLimitKeysMu.RUnlock()
+	}
+	if keyFunc == nil {
+		logging.Fatal("Init rate limit fatal: key %s of policy %s is not registered", cfg.Key, name)
+	}
+	return &RateLimitPolicy{
+		Name:    name,
+		Limiter: ratelimit.NewLimiter(client),
+		Limit:   ratelimit.Limit{Rate: cfg.Rate, Period: cfg.Period, Burst: cfg.Burst},
+		KeyFunc: keyFunc,
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/rbac"
+)
+
+// RequirePermission 校验当前用户拥有全部所需权限, 未鉴权返回401, 无权限返回403
+func RequirePermission(perms ...string) RouterHandler {
+	return middleware.RequirePermission(perms...)
+}
+
+func (m *Middleware) RequirePermission(perms ...string) RouterHandler {
+	return func(c *gin.Context) bool {
+		user := GetUser(c)
+		if user == nil {
+			return m.UnauthorizedResponse(c)
+		}
+		ok, err := rbac.HasPermission(c.Request.Context(), user.Uid, perms...)
+		if err != nil {
+			logging.Ctx(c).Error("Check permission %v err: %+v", perms, err)
+			return m.FailureResponse(c, exception.SERVER_ERROR_ERR)
+		}
+		if !ok {
+			return m.ForbiddenResponse(c)
+		}
+		return m.Next(c)
+	}
+}
+
+func (c *Controller) ForbiddenResponse(ctx *gin.Context) bool {
+	return c.FailureResponseWithCode(ctx, exception.FORBID_CODE, exception.FORBIDDEN_ERR)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// 内置中间件名称
+const (
+	MiddlewareTrace     = "trace"
+	MiddlewareAccessLog = "access_log"
+	MiddlewareMetrics   = "metrics"
+	MiddlewareRecovery  = "recovery"
+	MiddlewareRateLimit = "rate_limit"
+	MiddlewareAuth      = "auth"
+	MiddlewareCors      = "cors"
+	MiddlewareSecurity  = "security_headers"
+	MiddlewareBodyLimit = "body_limit"
+	MiddlewareTimeout   = "timeout"
+)
+
+// MiddlewareFactory 在初始化路由时创建中间件, 返回nil表示按配置不启用
+type MiddlewareFactory func() RouterHandler
+
+var (
+	middlewareFactories = make(map[string]MiddlewareFactory)
+	middlewareMu        sync.RWMutex
+)
+
+func init() {
+	RegisterMiddleware(MiddlewareTrace, middleware.Trace)
+	RegisterMiddleware(MiddlewareAccessLog, func() RouterHandler {
+		return middleware.AccessLog(&config.GetServerConfig().Log.Access)
+	})
+	RegisterMiddleware(MiddlewareMetrics, func() RouterHandler {
+		if !config.GetServerConfig().Metrics.Enabled {
+			return nil
+		}
+		return middleware.Metrics()
+	})
+	RegisterMiddleware(MiddlewareRecovery, middleware.Recovery)
+	RegisterMiddleware(MiddlewareRateLimit, func() RouterHandler {
+		limit := config.GetServerConfig().RateLimit
+		if limit <= 0 {
+			return nil
+		}
+		return middleware.RateLimiter(limit)
+	})
+	RegisterMiddleware(MiddlewareCors, func() RouterHandler {
+		cfg := &config.GetServerConfig().Cors
+		if utils.IsEmpty(cfg.AllowOrigins) {
+			return nil
+		}
+		return middleware.Cors(cfg)
+	})
+	RegisterMiddleware(MiddlewareSecurity, func() RouterHandler {
+		return middleware.SecurityHeaders(&config.GetServerConfig().Security)
+	})
+	RegisterMiddleware(MiddlewareBodyLimit, func() RouterHandler {
+		limit := config.GetServerConfig().MaxBodySize
+		if limit <= 0 {
+			return nil
+		}
+		return middleware.BodyLimit(limit)
+	})
+	RegisterMiddleware(MiddlewareTimeout, func() RouterHandler {
+		timeout := config.GetServerConfig().Timeout.Request
+		if timeout <= 0 {
+			return nil
+		}
+		return middleware.globalTimeout(timeout)
+	})
+	RegisterMiddleware(MiddlewareAuth, func() RouterHandler {
+		manager := GetAuthManager()
+		if manager == nil {
+			logging.Fatal("Init router fatal: middleware auth requires server.auth.secret")
+		}
+		return middleware.Auth(manager, config.GetServerConfig().Auth.Cookie)
+	})
+}
+
+// RegisterMiddleware 注册命名中间件, 可在server.middleware配置中按名称启用, 同名覆盖
+func RegisterMiddleware(name string, factory MiddlewareFactory) {
+	middlewareMu.Lock()
+	middlewareFactories[name] = factory
+	middlewareMu.Unlock()
+}
+
+func GetMiddlewareNames() []string {
+	middlewareMu.RLock()
+	defer middlewareMu.RUnlock()
+	names := make([]string, 0, len(middlewareFactories))
+	for name := range middlewareFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedMiddleware 按名称顺序创建中间件, 未注册的名称直接退出
+func namedMiddleware(names []string) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	for _, name := range names {
+		middlewareMu.RLock()
+		factory, ok := middlewareFactories[name]
+		middlewareMu.RUnlock()
+		if !ok {
+			logging.Fatal("Init router fatal: middleware %s is not registered, %v is available", name, GetMiddlewareNames())
+		}
+		if handler := factory(); handler != nil {
+			handlers = append(handlers, toRawHandler(handler))
+		}
+	}
+	return handlers
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// MethodAny 未指定方法或指定为ANY的路由响应全部方法
+const MethodAny = "ANY"
+
+// RouteInfo 已注册路由的元数据, 路由匹配后即可读取, 全局中间件中同样可用
+type RouteInfo struct {
+	Name       string        `json:"name,omitempty"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	Permission []string      `json:"permission,omitempty"`
+	Timeout    time.Duration `json:"timeout,omitempty"`
+	RateLimit  string        `json:"rate_limit,omitempty"`
+	Summary    string        `json:"summary,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+}
+
+var (
+	routes      = make(map[string]*RouteInfo)
+	namedRoutes = make(map[string]*RouteInfo)
+	routesMu    sync.RWMutex
+)
+
+func registerRoute(info *RouteInfo) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	if !utils.IsEmpty(info.Name) {
+		if exists, ok := namedRoutes[info.Name]; ok {
+			logging.Fatal("Init router fatal: route name %s is used by both %s %s and %s %s", info.Name, exists.Method, exists.Path, info.Method, info.Path)
+		}
+		namedRoutes[info.Name] = info
+	}
+	routes[routeKey(info.Method, info.Path)] = info
+}
+
+// GetRoute 当前请求匹配的路由, 健康检查等未通过路由声明注册的接口返回nil
+func GetRoute(c *gin.Context) *RouteInfo {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	if info, ok := routes[routeKey(c.Request.Method, c.FullPath())]; ok {
+		return info
+	}
+	return routes[routeKey(MethodAny, c.FullPath())]
+}
+
+func GetRouteByName(name string) *RouteInfo {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	return namedRoutes[name]
+}
+
+// GetRoutes 按路径及方法排序的全部路由
+func GetRoutes() []*RouteInfo {
+	routesMu.RLock()
+	list := make([]*RouteInfo, 0, len(routes))
+	for _, info := range routes {
+		list = append(list, info)
+	}
+	routesMu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+	return list
+}
+
+// dumpRoutes 启动时输出路由列表, 便于核对路径及各路由的权限, 超时和限流设置
+func dumpRoutes() {
+	list := GetRoutes()
+	logging.Info("Init router: %d routes registered", len(list))
+	for _, info := range list {
+		var meta []string
+		if !utils.IsEmpty(info.Name) {
+			meta = append(meta, "name="+info.Name)
+		}
+		if !utils.IsEmpty(info.Permission) {
+			meta = append(meta, "permission="+strings.Join(info.Permission, ","))
+		}
+		if info.Timeout > 0 {
+			meta = append(meta, "timeout="+info.Timeout.String())
+		}
+		if !utils.IsEmpty(info.RateLimit) {
+			meta = append(meta, "rate_limit="+info.RateLimit)
+		}
+		logging.Debug("Route %-7s %s %s", info.Method, info.Path, strings.Join(meta, " "))
+	}
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
@@ -2,11 +2,15 @@ package http
 
 import (
 	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/EvisuXiao/andrews-common/config"
 	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
@@ -15,41 +19,96 @@ type RouterHandler func(*gin.Context) bool
 
 type MainRouterGroup struct {
 	Path       string
-	Middleware interface{} // HandlerFunc, []HandlerFunc
+	Middleware interface{} // RouterHandler, gin.HandlerFunc, 中间件名称及其切片
 	Groups     []*RouterGroup
 }
 
 type RouterGroup struct {
 	Path       string
-	Middleware interface{} // HandlerFunc, []HandlerFunc
+	Middleware interface{} // RouterHandler, gin.HandlerFunc, 中间件名称及其切片
 	Routers    []*RouterItem
+	// Groups 子路由组, 路径及中间件在当前路由组基础上叠加
+	Groups []*RouterGroup
 }
 
 type RouterItem struct {
+	// Method 任意http方法, 为空或为ANY时响应全部方法
 	Method   string
 	Path     string
-	Handlers interface{} // HandlerFunc, []HandlerFunc
+	Handlers interface{} // RouterHandler, gin.HandlerFunc, 中间件名称及其切片
+	// Name 路由名称, 不可重复, 可通过GetRouteByName查找
+	Name string
+	// Permission 访问所需权限, 多个权限需同时满足, 需在鉴权中间件之后使用
+	Permission []string
+	// Timeout 路由单独的处理超时, 覆盖server.timeout.request
+	Timeout time.Duration
+	// RateLimit 路由单独使用的server.rate_limits限流策略名称
+	RateLimit string
+	// Summary, Tags, Request及Response仅用于生成接口文档, Request及Response为对应类型的零值
+	Summary  string
+	Tags     []string
+	Request  interface{}
+	Response interface{}
 }
 
 func InitRouter(groups ...*MainRouterGroup) *gin.Engine {
 	setMode()
 	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
-	rateLimit := config.GetServerConfig().RateLimit
-	if rateLimit > 0 {
-		r.Use(toRawHandler(middleware.RateLimiter(rateLimit)))
-	}
+	setTrustedProxies(r)
+	// 健康检查, 指标及文档接口不经过全局中间件, 避免被限流及产生访问日志
+	r.GET(LivenessPath, toRawHandler(healthCtl.liveness))
+	r.GET(ReadinessPath, toRawHandler(healthCtl.readiness))
+	initMetrics(r)
+	initDocs(r, groups)
+	registerRateLimitPolicies()
+	r.Use(namedMiddleware(config.GetServerConfig().Middleware.Global)...)
 	r.GET("/", func(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "Hello "+config.GetServiceName())
 		ctx.Abort()
 	})
 	for _, group := range groups {
-		initRouterGroup(r.Group(group.Path, toRawHandlers(group.Middleware)...), group.Groups)
+		initRouterGroup(newGroup(&r.RouterGroup, group.Path, group.Middleware), group.Groups)
 	}
+	dumpRoutes()
 	return r
 }
 
+// setTrustedProxies 配置可信代理后ClientIP仅信任来自这些代理的转发头
+func setTrustedProxies(r *gin.Engine) {
+	proxies := config.GetServerConfig().TrustedProxies
+	trustProxyHeaders = !utils.IsEmpty(proxies)
+	if !trustProxyHeaders {
+		return
+	}
+	if err := r.SetTrustedProxies(proxies); utils.HasErr(err) {
+		logging.Fatal("Init router fatal: invalid server.trusted_proxies: %+v", err)
+	}
+}
+
+// initMetrics 开启指标时暴露指标接口, 未配置单独端口时在当前服务暴露
+func initMetrics(r *gin.Engine) {
+	cfg := config.GetServerConfig().Metrics
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.Port > 0 {
+		metrics.StartServer(cfg.Port, cfg.Path)
+		return
+	}
+	r.GET(cfg.Path, gin.WrapH(metrics.Handler()))
+}
+
+// newGroup 创建路由组, 配置中按完整路径启用的中间件先于代码中声明的中间件执行
+func newGroup(parent *gin.RouterGroup, path string, middleware interface{}) *gin.RouterGroup {
+	group := parent.Group(path)
+	// 路径为空的子路由组与上层路径相同, 不再重复添加配置中的中间件
+	if group.BasePath() != parent.BasePath() {
+		group.Use(namedMiddleware(config.GetServerConfig().Middleware.Groups[group.BasePath()])...)
+	}
+	group.Use(toRawHandlers(middleware)...)
+	return group
+}
+
 func setMode() {
 	if config.IsLocalEnv() {
 		gin.SetMode(gin.DebugMode)
@@ -60,23 +119,37 @@ func setMode() {
 	}
 }
 
-// 将自定义handler转回默认handler
+// 将自定义handler转回默认handler, 支持RouterHandler, gin.HandlerFunc, 已注册的中间件名称及其切片
 func toRawHandlers(handlers interface{}) []gin.HandlerFunc {
 	var rawFunc []gin.HandlerFunc
 	if utils.IsEmpty(handlers) {
 		return rawFunc
 	}
-	var customFunc []RouterHandler
 	switch v := handlers.(type) {
 	case func(*gin.Context) bool:
-		customFunc = []RouterHandler{v}
+		rawFunc = append(rawFunc, toRawHandler(v))
+	case RouterHandler:
+		rawFunc = append(rawFunc, toRawHandler(v))
 	case []RouterHandler:
-		customFunc = v
+		for _, f := range v {
+			rawFunc = append(rawFunc, toRawHandler(f))
+		}
+	case func(*gin.Context):
+		rawFunc = append(rawFunc, v)
+	case gin.HandlerFunc:
+		rawFunc = append(rawFunc, v)
+	case []gin.HandlerFunc:
+		rawFunc = append(rawFunc, v...)
+	case string:
+		rawFunc = namedMiddleware([]string{v})
+	case []string:
+		rawFunc = namedMiddleware(v)
+	case []interface{}:
+		for _, item := range v {
+			rawFunc = append(rawFunc, toRawHandlers(item)...)
+		}
 	default:
-		logging.Fatal("Init router fatal: unsupported handler type")
-	}
-	for _, f := range customFunc {
-		rawFunc = append(rawFunc, toRawHandler(f))
+		logging.Fatal("Init router fatal: unsupported handler type %T", handlers)
 	}
 	return rawFunc
 }
@@ -89,21 +162,56 @@ func toRawHandler(handler RouterHandler) gin.HandlerFunc {
 
 func initRouterGroup(engine *gin.RouterGroup, routers []*RouterGroup) {
 	for _, group := range routers {
-		apiGroup := engine.Group(group.Path, toRawHandlers(group.Middleware)...)
+		apiGroup := newGroup(engine, group.Path, group.Middleware)
 		for _, router := range group.Routers {
-			ginHandlers := toRawHandlers(router.Handlers)
-			switch router.Method {
-			case http.MethodGet:
-				apiGroup.GET(router.Path, ginHandlers...)
-			case http.MethodPost:
-				apiGroup.POST(router.Path, ginHandlers...)
-			case http.MethodPut:
-				apiGroup.PUT(router.Path, ginHandlers...)
-			case http.MethodDelete:
-				apiGroup.DELETE(router.Path, ginHandlers...)
-			default:
-				apiGroup.Any(router.Path, ginHandlers...)
-			}
+			initRouterItem(apiGroup, router)
 		}
+		initRouterGroup(apiGroup, group.Groups)
+	}
+}
+
+// initRouterItem 注册路由及其元数据, 路由级中间件依次为超时, 限流及权限校验
+func initRouterItem(group *gin.RouterGroup, router *RouterItem) {
+	method := strings.ToUpper(router.Method)
+	if utils.IsEmpty(method) {
+		method = MethodAny
+	}
+	var ginHandlers []gin.HandlerFunc
+	if router.Timeout > 0 {
+		ginHandlers = append(ginHandlers, toRawHandler(middleware.Timeout(router.Timeout)))
+	}
+	if !utils.IsEmpty(router.RateLimit) {
+		ginHandlers = append(ginHandlers, namedMiddleware([]string{MiddlewareRateLimit + ":" + router.RateLimit})...)
+	}
+	if !utils.IsEmpty(router.Permission) {
+		ginHandlers = append(ginHandlers, toRawHandler(RequirePermission(router.Permission...)))
+	}
+	ginHandlers = append(ginHandlers, toRawHandlers(router.Handlers)...)
+	registerRoute(&RouteInfo{
+		Name:       router.Name,
+		Method:     method,
+		Path:       joinPath(group.BasePath(), router.Path),
+		Permission: router.Permission,
+		Timeout:    router.Timeout,
+		RateLimit:  router.RateLimit,
+		Summary:    router.Summary,
+		Tags:       router.Tags,
+	})
+	if method == MethodAny {
+		group.Any(router.Path, ginHandlers...)
+		return
+	}
+	group.Handle(method, router.Path, ginHandlers...)
+}
+
+// joinPath 与gin计算路由完整路径的方式一致, 保留结尾的斜杠
+func joinPath(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	joined := path.Join(base, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
 	}
+	return joined
 }
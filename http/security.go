@@ -0,0 +1,129 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// Cors 校验Origin并输出跨域响应头, 预检请求直接以204应答, 不允许的来源预检返回403
+func (m *Middleware) Cors(cfg *config.Cors) RouterHandler {
+	methods := strings.Join(cfg.AllowMethods, ", ")
+	headers := strings.Join(cfg.AllowHeaders, ", ")
+	expose := strings.Join(cfg.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	allowAll := utils.InSlice("*", cfg.AllowOrigins)
+	return func(c *gin.Context) bool {
+		origin := c.GetHeader("Origin")
+		if utils.IsEmpty(origin) {
+			return m.Next(c)
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && !utils.IsEmpty(c.GetHeader("Access-Control-Request-Method"))
+		if !allowAll && !matchOrigin(cfg.AllowOrigins, origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return true
+			}
+			return m.Next(c)
+		}
+		if allowAll && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return true
+		}
+		if !utils.IsEmpty(expose) {
+			c.Header("Access-Control-Expose-Headers", expose)
+		}
+		return m.Next(c)
+	}
+}
+
+// matchOrigin 支持完整匹配及https://*.example.com形式的子域名通配
+func matchOrigin(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+		if i := strings.Index(o, "*."); i > 0 {
+			scheme, domain := o[:i], o[i+1:]
+			if strings.HasPrefix(origin, scheme) && strings.HasSuffix(strings.ToLower(origin), strings.ToLower(domain)) && len(origin) > len(scheme)+len(domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *Middleware) SecurityHeaders(cfg *config.Security) RouterHandler {
+	hsts := ""
+	if cfg.Hsts > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int(cfg.Hsts.Seconds()))
+		if cfg.HstsIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	headers := map[string]string{
+		"X-Content-Type-Options":  cfg.ContentTypeOptions,
+		"X-Frame-Options":         cfg.FrameOptions,
+		"Referrer-Policy":         cfg.ReferrerPolicy,
+		"Content-Security-Policy": cfg.ContentSecurityPolicy,
+	}
+	return func(c *gin.Context) bool {
+		for k, v := range headers {
+			if !utils.IsEmpty(v) {
+				c.Header(k, v)
+			}
+		}
+		if !utils.IsEmpty(hsts) && isHttps(c) {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		return m.Next(c)
+	}
+}
+
+// isHttps 仅在配置了server.trusted_proxies且对端为可信代理时读取X-Forwarded-Proto, 与ClientIP一致
+func isHttps(c *gin.Context) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	if !trustProxyHeaders {
+		return false
+	}
+	if _, trusted := c.RemoteIP(); !trusted {
+		return false
+	}
+	return strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
+
+// BodyLimit 限制请求体大小, 声明的长度超出时直接返回413, 未声明长度时读取超出部分返回错误
+func (m *Middleware) BodyLimit(limit int64) RouterHandler {
+	return func(c *gin.Context) bool {
+		if c.Request.ContentLength > limit {
+			return m.FailureResponseWithCode(c, http.StatusRequestEntityTooLarge, exception.CustomErrWrapper("request body is larger than %d bytes", limit))
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		return m.Next(c)
+	}
+}
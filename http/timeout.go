@@ -0,0 +1,186 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+)
+
+// Timeout 为请求上下文设置截止时间, 数据库及redis调用传入ctx.Request.Context()即可在到期后中止
+// 后续中间件及处理函数在协程中执行, 响应先写入缓冲, 到期时立即返回超时错误并丢弃处理函数之后的输出
+// gin.Context会被复用, 已响应超时后仍等待处理函数返回才结束本次请求, 客户端不受影响
+func (m *Middleware) Timeout(timeout time.Duration) RouterHandler {
+	return func(c *gin.Context) bool {
+		return m.withTimeout(c, timeout)
+	}
+}
+
+// globalTimeout 全局超时, 路由单独设置了超时时以路由为准
+func (m *Middleware) globalTimeout(timeout time.Duration) RouterHandler {
+	return func(c *gin.Context) bool {
+		if route := GetRoute(c); route != nil && route.Timeout > 0 {
+			return m.Next(c)
+		}
+		return m.withTimeout(c, timeout)
+	}
+}
+
+func (m *Middleware) withTimeout(c *gin.Context, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	w := c.Writer
+	tw := newTimeoutWriter(w)
+	c.Writer = tw
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+		c.Next()
+	}()
+	select {
+	case p := <-done:
+		c.Writer = w
+		if p != nil {
+			panic(p)
+		}
+		tw.flush()
+		return true
+	case <-ctx.Done():
+	}
+	tw.stop()
+	// 处理函数仍在使用gin.Context, 此处只使用局部变量, 客户端断开导致的取消无需响应
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logging.Ctx(ctx).Warning("Request timeout after %s", timeout)
+		writeTimeout(w)
+	}
+	// 等待处理函数返回后才可归还gin.Context
+	if p := <-done; p != nil {
+		logging.Ctx(ctx).Error("Request panic after timeout: %v", p)
+	}
+	c.Writer = w
+	c.Abort()
+	return true
+}
+
+// writeTimeout 直接写入原始响应并指定长度, 处理函数仍在执行时客户端也能收到完整响应
+func writeTimeout(w gin.ResponseWriter) {
+	output := NewOutput(exception.TIMEOUT_CODE)
+	output.SetMessage(exception.TIMEOUT_MSG)
+	body, _ := json.Marshal(output)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+	w.Flush()
+}
+
+// timeoutWriter 缓冲处理函数的响应, 未超时时在请求结束前写入原始响应, 超时后丢弃
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header   http.Header
+	body     bytes.Buffer
+	mu       sync.Mutex
+	status   int
+	written  bool
+	timedOut bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, header: w.Header().Clone(), status: http.StatusOK}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	w.written = true
+	w.mu.Unlock()
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// Flush 响应整体缓冲, 流式输出在超时中间件内不生效
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) stop() {
+	w.mu.Lock()
+	w.timedOut = true
+	w.mu.Unlock()
+}
+
+// flush 处理函数返回后将缓冲的响应写入原始响应
+func (w *timeoutWriter) flush() {
+	dst := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	for k := range dst {
+		if _, ok := w.header[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.written {
+		return
+	}
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
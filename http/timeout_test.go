@@ -0,0 +1,102 @@
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EvisuXiao/andrews-common/exception"
+)
+
+func newTimeoutServer(t *testing.T, timeout time.Duration, handler gin.HandlerFunc) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", toRawHandler(middleware.Timeout(timeout)), handler)
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func getOutput(t *testing.T, url string) (*http.Response, *ApiOutput) {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("request err: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("read body err: %v", err)
+	}
+	output := &ApiOutput{}
+	if err = json.Unmarshal(body, output); err != nil {
+		t.Fatalf("unmarshal %q err: %v", body, err)
+	}
+	return response, output
+}
+
+// TestTimeoutSlowHandler 处理函数忽略ctx时, 到期即返回超时错误, 之后的输出被丢弃
+func TestTimeoutSlowHandler(t *testing.T) {
+	var finished, lateWriteErr int32
+	s := newTimeoutServer(t, 50*time.Millisecond, func(c *gin.Context) {
+		time.Sleep(300 * time.Millisecond)
+		if _, err := c.Writer.Write([]byte(`{"code":0}`)); err != nil {
+			atomic.StoreInt32(&lateWriteErr, 1)
+		}
+		atomic.StoreInt32(&finished, 1)
+	})
+	start := time.Now()
+	_, output := getOutput(t, s.URL)
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("timeout response took %s, want about 50ms", elapsed)
+	}
+	if output.Code != exception.TIMEOUT_CODE || output.Message != exception.TIMEOUT_MSG {
+		t.Fatalf("output = %+v, want timeout error", output)
+	}
+	if atomic.LoadInt32(&finished) != 0 {
+		t.Fatal("handler should still be running when timeout response is received")
+	}
+	time.Sleep(400 * time.Millisecond)
+	if atomic.LoadInt32(&lateWriteErr) != 1 {
+		t.Fatal("late handler output should be dropped")
+	}
+}
+
+func TestTimeoutFastHandler(t *testing.T) {
+	s := newTimeoutServer(t, time.Second, func(c *gin.Context) {
+		c.Header("X-Handler", "1")
+		c.JSON(http.StatusCreated, NewOutput(exception.SUCCESS_CODE))
+	})
+	response, output := getOutput(t, s.URL)
+	if response.StatusCode != http.StatusCreated || response.Header.Get("X-Handler") != "1" {
+		t.Fatalf("status = %d, header = %q", response.StatusCode, response.Header.Get("X-Handler"))
+	}
+	if output.Code != exception.SUCCESS_CODE {
+		t.Fatalf("output = %+v", output)
+	}
+}
+
+// TestTimeoutContextDeadline 处理函数可通过请求上下文感知超时
+func TestTimeoutContextDeadline(t *testing.T) {
+	var cancelled int32
+	s := newTimeoutServer(t, 50*time.Millisecond, func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			atomic.StoreInt32(&cancelled, 1)
+		case <-time.After(time.Second):
+		}
+	})
+	_, output := getOutput(t, s.URL)
+	if output.Code != exception.TIMEOUT_CODE {
+		t.Fatalf("output = %+v, want timeout error", output)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Fatal("request context should be cancelled on timeout")
+	}
+}
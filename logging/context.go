@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EvisuXiao/andrews-common/pkg/trace"
+)
+
+// 上下文中的追踪字段, 默认从trace.SpanContext中读取, 也可在gin.Context中直接通过Set(key, value)设置
+const (
+	TraceIdKey   = "trace_id"
+	SpanIdKey    = "span_id"
+	RequestIdKey = "request_id"
+)
+
+type contextKey string
+
+var (
+	contextKeys   = []string{TraceIdKey, SpanIdKey, RequestIdKey}
+	contextKeysMu sync.RWMutex
+)
+
+// RegisterContextKey 注册额外需要从上下文中提取到日志的字段
+func RegisterContextKey(keys ...string) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	for _, key := range keys {
+		exists := false
+		for _, k := range contextKeys {
+			if k == key {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			contextKeys = append(contextKeys, key)
+		}
+	}
+}
+
+// WithContextValue 将追踪字段写入上下文, 供Ctx提取
+func WithContextValue(ctx context.Context, key string, value interface{}) context.Context {
+	return context.WithValue(ctx, contextKey(key), value)
+}
+
+// ContextValue 读取WithContextValue写入的字段, 兼容gin.Context中通过Set设置的字段
+func ContextValue(ctx context.Context, key string) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(contextKey(key)); v != nil {
+		return v
+	}
+	if v := ctx.Value(key); v != nil {
+		return v
+	}
+	if sc := trace.FromContext(ctx); sc != nil {
+		switch key {
+		case TraceIdKey:
+			return sc.TraceId
+		case SpanIdKey:
+			return sc.SpanId
+		case RequestIdKey:
+			return sc.RequestId
+		}
+	}
+	return nil
+}
+
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	contextKeysMu.RLock()
+	defer contextKeysMu.RUnlock()
+	var fields []Field
+	for _, key := range contextKeys {
+		if v := ContextValue(ctx, key); v != nil && v != "" {
+			fields = append(fields, F(key, v))
+		}
+	}
+	return fields
+}
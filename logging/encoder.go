@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	textTimeFormat = "2006/01/02 15:04:05"
+	jsonTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+type Encoder interface {
+	Encode(e *Entry) []byte
+}
+
+// TextEncoder 兼容原有格式: 2006/01/02 15:04:05 [INFO] msg key=value
+type TextEncoder struct{}
+
+// JsonEncoder 每行一个json对象, 固定包含time, level, msg字段
+type JsonEncoder struct{}
+
+func NewEncoder(format string) Encoder {
+	if strings.ToLower(format) == FormatJson {
+		return &JsonEncoder{}
+	}
+	return &TextEncoder{}
+}
+
+func (*TextEncoder) Encode(e *Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(e.Time.Format(textTimeFormat))
+	buf.WriteString(" [")
+	buf.WriteString(e.Level)
+	buf.WriteString("] ")
+	buf.WriteString(strings.TrimRight(e.Message, "\n"))
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		buf.WriteString(textValue(f.Value))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func (*JsonEncoder) Encode(e *Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJsonValue(&buf, e.Time.Format(jsonTimeFormat))
+	buf.WriteString(`,"level":`)
+	writeJsonValue(&buf, e.Level)
+	buf.WriteString(`,"msg":`)
+	writeJsonValue(&buf, strings.TrimRight(e.Message, "\n"))
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		writeJsonValue(&buf, f.Key)
+		buf.WriteByte(':')
+		writeJsonValue(&buf, fieldValue(f.Value))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// fieldValue error及time.Duration等类型转为可读的字符串
+func fieldValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return v
+}
+
+func textValue(v interface{}) string {
+	var s string
+	switch val := fieldValue(v).(type) {
+	case string:
+		s = val
+	case []byte:
+		s = string(val)
+	default:
+		s = fmt.Sprintf("%+v", val)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func writeJsonValue(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", v))
+	}
+	buf.Write(b)
+}
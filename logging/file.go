@@ -0,0 +1,242 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	rotateTimeFormat = "20060102T150405"
+	compressSuffix   = ".gz"
+)
+
+type FileOption struct {
+	Path string
+	// MaxSize 单个文件最大字节数, 超过后轮转, 0为不限制
+	MaxSize int64
+	// Interval 按时间轮转的周期, 如24h, 0为不按时间轮转
+	Interval time.Duration
+	// MaxAge 轮转文件的最长保留时间, 0为不限制
+	MaxAge time.Duration
+	// MaxCount 轮转文件的最多保留个数, 0为不限制
+	MaxCount int
+	// Compress 轮转后是否gzip压缩
+	Compress bool
+}
+
+// FileWriter 支持按大小及时间轮转的日志文件, 轮转文件命名为 <name>-<time><ext>
+type FileWriter struct {
+	opt       FileOption
+	mu        sync.Mutex
+	file      *os.File
+	size      int64
+	rotateAt  time.Time
+	cleanupMu sync.Mutex
+}
+
+func NewFileWriter(opt *FileOption) (*FileWriter, error) {
+	w := &FileWriter{opt: *opt}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.open(); utils.HasErr(err) {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); utils.HasErr(err) {
+			return 0, err
+		}
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); utils.HasErr(err) {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件, 之后再写入时会重新打开
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Rotate 立即轮转当前文件
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+func (w *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.opt.Path), 0755); utils.HasErr(err) {
+		return err
+	}
+	f, err := os.OpenFile(w.opt.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if utils.HasErr(err) {
+		return err
+	}
+	info, err := f.Stat()
+	if utils.HasErr(err) {
+		_ = f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	if w.opt.Interval > 0 {
+		w.rotateAt = time.Now().Truncate(w.opt.Interval).Add(w.opt.Interval)
+	}
+	return nil
+}
+
+func (w *FileWriter) shouldRotate(n int64) bool {
+	if w.opt.MaxSize > 0 && w.size > 0 && w.size+n > w.opt.MaxSize {
+		return true
+	}
+	return w.opt.Interval > 0 && !time.Now().Before(w.rotateAt)
+}
+
+func (w *FileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); utils.HasErr(err) {
+			return err
+		}
+		w.file = nil
+	}
+	if _, err := os.Stat(w.opt.Path); err == nil {
+		if err = os.Rename(w.opt.Path, w.rotatedPath()); utils.HasErr(err) {
+			return err
+		}
+	}
+	if err := w.open(); utils.HasErr(err) {
+		return err
+	}
+	go w.cleanup()
+	return nil
+}
+
+func (w *FileWriter) rotatedPath() string {
+	ext := filepath.Ext(w.opt.Path)
+	prefix := strings.TrimSuffix(w.opt.Path, ext) + "-" + time.Now().Format(rotateTimeFormat)
+	path := prefix + ext
+	for i := 1; fileExists(path) || fileExists(path+compressSuffix); i++ {
+		path = fmt.Sprintf("%s.%d%s", prefix, i, ext)
+	}
+	return path
+}
+
+// cleanup 压缩轮转文件并按保留策略删除过期文件
+func (w *FileWriter) cleanup() {
+	w.cleanupMu.Lock()
+	defer w.cleanupMu.Unlock()
+	files, err := w.rotatedFiles()
+	if utils.HasErr(err) {
+		return
+	}
+	var remain []string
+	for i, path := range files {
+		if (w.opt.MaxCount > 0 && i >= w.opt.MaxCount) || w.expired(path) {
+			_ = os.Remove(path)
+			continue
+		}
+		remain = append(remain, path)
+	}
+	if !w.opt.Compress {
+		return
+	}
+	for _, path := range remain {
+		if !strings.HasSuffix(path, compressSuffix) {
+			_ = compressFile(path)
+		}
+	}
+}
+
+// rotatedFiles 当前日志的全部轮转文件, 按时间由新到旧
+func (w *FileWriter) rotatedFiles() ([]string, error) {
+	ext := filepath.Ext(w.opt.Path)
+	prefix := filepath.Base(strings.TrimSuffix(w.opt.Path, ext)) + "-"
+	dir := filepath.Dir(w.opt.Path)
+	entries, err := os.ReadDir(dir)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(strings.TrimSuffix(name, compressSuffix), prefix)
+		if len(stamp) < len(rotateTimeFormat) {
+			continue
+		}
+		if _, err = time.ParseInLocation(rotateTimeFormat, stamp[:len(rotateTimeFormat)], time.Local); utils.HasErr(err) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+func (w *FileWriter) expired(path string) bool {
+	if w.opt.MaxAge <= 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > w.opt.MaxAge
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if utils.HasErr(err) {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if utils.HasErr(err) {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); utils.HasErr(err) {
+		_ = gz.Close()
+		_ = dst.Close()
+		_ = os.Remove(path + compressSuffix)
+		return err
+	}
+	if err = gz.Close(); utils.HasErr(err) {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); utils.HasErr(err) {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type Logger interface {
+	// With 返回附带字段的子日志, 不影响原日志
+	With(fields ...Field) Logger
+	// Ctx 返回附带上下文中追踪字段(trace_id, request_id等)的子日志
+	Ctx(ctx context.Context) Logger
+	Print(level, msg string, args ...interface{})
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warning(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	Fatal(msg string, args ...interface{})
+}
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  []Field
+}
+
+type Option struct {
+	// Level 最低输出级别, 默认DEBUG
+	Level string
+	// Encoder 默认文本格式
+	Encoder Encoder
+	// Writer 默认标准错误输出, 设置Sinks时忽略
+	Writer io.Writer
+	// Sinks 同时输出到多个目标, 如标准输出及文件
+	Sinks []*Sink
+}
+
+// Sink 日志输出目标, Level及Encoder为空时使用Option中的设置, Level低于Option中的级别时无效
+type Sink struct {
+	Level   string
+	Encoder Encoder
+	Writer  io.Writer
+}
+
+type output struct {
+	mu      sync.Mutex
+	level   int
+	encoder Encoder
+	writer  io.Writer
+}
+
+type logger struct {
+	level  int
+	outs   []*output
+	fields []Field
+}
+
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+func New(opt *Option) Logger {
+	l := &logger{level: levelWeights[ParseLevel(opt.Level)]}
+	sinks := opt.Sinks
+	if len(sinks) == 0 {
+		sinks = []*Sink{{Writer: opt.Writer}}
+	}
+	for _, sink := range sinks {
+		out := &output{
+			level:   l.level,
+			encoder: sink.Encoder,
+			writer:  sink.Writer,
+		}
+		if sink.Level != "" {
+			out.level = levelWeights[ParseLevel(sink.Level)]
+		}
+		if out.encoder == nil {
+			out.encoder = opt.Encoder
+		}
+		if out.encoder == nil {
+			out.encoder = &TextEncoder{}
+		}
+		if out.writer == nil {
+			out.writer = os.Stderr
+		}
+		l.outs = append(l.outs, out)
+	}
+	return l
+}
+
+func (l *logger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &logger{level: l.level, outs: l.outs, fields: merged}
+}
+
+func (l *logger) Ctx(ctx context.Context) Logger {
+	return l.With(ContextFields(ctx)...)
+}
+
+func (l *logger) Print(level, msg string, args ...interface{}) {
+	level = ParseLevel(level)
+	weight := levelWeights[level]
+	if weight < l.level {
+		return
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+	}
+	for _, out := range l.outs {
+		if weight >= out.level {
+			out.write(e)
+		}
+	}
+}
+
+func (l *logger) Debug(msg string, args ...interface{}) {
+	l.Print(LevelDebug, msg, args...)
+}
+
+func (l *logger) Info(msg string, args ...interface{}) {
+	l.Print(LevelInfo, msg, args...)
+}
+
+func (l *logger) Warning(msg string, args ...interface{}) {
+	l.Print(LevelWarning, msg, args...)
+}
+
+func (l *logger) Error(msg string, args ...interface{}) {
+	l.Print(LevelError, msg, args...)
+}
+
+func (l *logger) Fatal(msg string, args ...interface{}) {
+	l.Print(LevelFatal, msg, args...)
+	os.Exit(1)
+}
+
+func (o *output) write(e *Entry) {
+	b := o.encoder.Encode(e)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, _ = o.writer.Write(b)
+}
@@ -1,9 +1,15 @@
 package logging
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/utils"
 )
 
 const (
@@ -14,10 +20,125 @@ const (
 	LevelFatal   = "FATAL"
 )
 
+const (
+	FormatText = "text"
+	FormatJson = "json"
+)
+
+var levelWeights = map[string]int{
+	LevelDebug:   0,
+	LevelInfo:    1,
+	LevelWarning: 2,
+	LevelError:   3,
+	LevelFatal:   4,
+}
+
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+)
+
+const megabyte = 1 << 20
+
+var (
+	defaultLogger Logger = New(&Option{})
+	defaultMu     sync.RWMutex
+	closers       []io.Closer
+)
+
+// Init 按服务配置设置默认日志的最低级别, 输出格式及输出目标, 需在配置加载后调用
+func Init() {
+	cfg := config.GetServerConfig().Log
+	opt := &Option{
+		Level:   cfg.Level,
+		Encoder: NewEncoder(cfg.Format),
+	}
+	var files []io.Closer
+	for _, s := range cfg.Sinks {
+		sink := &Sink{Level: s.Level}
+		if s.Format != "" {
+			sink.Encoder = NewEncoder(s.Format)
+		}
+		switch s.Type {
+		case SinkStdout:
+			sink.Writer = os.Stdout
+		case SinkStderr:
+			sink.Writer = os.Stderr
+		case SinkFile:
+			w, err := NewFileWriter(&FileOption{
+				Path:     logFilePath(s.Path),
+				MaxSize:  int64(s.MaxSize) * megabyte,
+				Interval: s.Interval,
+				MaxAge:   s.MaxAge,
+				MaxCount: s.MaxCount,
+				Compress: s.Compress,
+			})
+			if utils.HasErr(err) {
+				Fatal("Init fatal: open log file %s err: %+v", s.Path, err)
+			}
+			sink.Writer = w
+			files = append(files, w)
+		}
+		opt.Sinks = append(opt.Sinks, sink)
+	}
+	SetDefault(New(opt))
+	_ = Close()
+	closers = files
+}
+
+// Close 关闭日志文件, 之后写入时会重新打开
+func Close() error {
+	var err error
+	for _, c := range closers {
+		if e := c.Close(); utils.HasErr(e) {
+			err = e
+		}
+	}
+	return err
+}
+
+func logFilePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return config.AppFilePath(path)
+}
+
+func GetDefault() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+func SetDefault(l Logger) {
+	defaultMu.Lock()
+	defaultLogger = l
+	defaultMu.Unlock()
+}
+
+// ParseLevel 不区分大小写解析日志级别, 无法识别时返回DEBUG
+func ParseLevel(level string) string {
+	level = strings.ToUpper(level)
+	if level == "WARN" {
+		return LevelWarning
+	}
+	if _, ok := levelWeights[level]; ok {
+		return level
+	}
+	return LevelDebug
+}
+
+func With(fields ...Field) Logger {
+	return GetDefault().With(fields...)
+}
+
+func Ctx(ctx context.Context) Logger {
+	return GetDefault().Ctx(ctx)
+}
+
 func Print(level, msg string, args ...interface{}) {
-	msg += "\n"
-	msg = fmt.Sprintf("[%s] %s", level, msg)
-	log.Printf(msg, args...)
+	GetDefault().Print(level, msg, args...)
 }
 
 func Debug(msg string, args ...interface{}) {
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	servers   []*http.Server
+	serversMu sync.Mutex
+)
+
+var (
+	HttpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of http requests by route and status.",
+	}, []string{"method", "route", "status"})
+	HttpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of http requests by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	GrpcServerHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total number of grpc calls handled by the server by method and code.",
+	}, []string{"method", "code"})
+	GrpcServerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of grpc calls handled by the server by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+	GrpcClientHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_handled_total",
+		Help: "Total number of grpc calls made by the client by method and code.",
+	}, []string{"method", "code"})
+	GrpcClientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Latency of grpc calls made by the client by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	DbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_operation_duration_seconds",
+		Help:    "Latency of database operations by database, operation and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"database", "operation", "result"})
+
+	RedisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_duration_seconds",
+		Help:    "Latency of redis commands by client, command and result.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"client", "command", "result"})
+
+	CronRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_job_runs_total",
+		Help: "Total number of cron job runs.",
+	}, []string{"job"})
+	CronFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_job_failures_total",
+		Help: "Total number of failed cron job runs.",
+	}, []string{"job"})
+	CronDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cron_job_duration_seconds",
+		Help:    "Duration of cron job runs.",
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"job"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequests, HttpDuration,
+		GrpcServerHandled, GrpcServerDuration, GrpcClientHandled, GrpcClientDuration,
+		DbDuration, RedisDuration,
+		CronRuns, CronFailures, CronDuration,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// StartServer 在单独的端口暴露指标, 用于grpc等非http服务
+func StartServer(port int, path string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(path, Handler())
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); utils.HasErr(err) && err != http.ErrServerClosed {
+			logging.Error("Start metrics server with port %d err: %+v", port, err)
+		}
+	}()
+	serversMu.Lock()
+	servers = append(servers, srv)
+	serversMu.Unlock()
+	logging.Info("Metrics is exposed at :%d%s", port, path)
+	return srv
+}
+
+// Shutdown 关闭StartServer启动的指标服务, 随主服务优雅退出时调用
+func Shutdown(ctx context.Context) error {
+	serversMu.Lock()
+	list := servers
+	servers = nil
+	serversMu.Unlock()
+	for _, srv := range list {
+		if err := srv.Shutdown(ctx); utils.HasErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterDB 上报数据库连接池状态
+func RegisterDB(name string, db *sql.DB) {
+	if err := Registry.Register(collectors.NewDBStatsCollector(db, name)); utils.HasErr(err) {
+		logging.Warning("register database %s metrics err: %+v", name, err)
+	}
+}
+
+// Register 注册自定义指标
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := Registry.Register(c); utils.HasErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Since 距开始时间的秒数
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// Result 按错误返回结果标签
+func Result(err error) string {
+	if utils.HasErr(err) {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,343 @@
+package apollo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/curl"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	defaultCluster  = "default"
+	defaultEnv      = "DEV"
+	defaultOperator = "apollo"
+	formatProps     = "properties"
+	contentKey      = "content"
+
+	pollTimeout   = 90 * time.Second
+	retryInterval = time.Second
+)
+
+type ConfigClient struct {
+	cfg           *constants.Apollo
+	client        *http.Client
+	mu            sync.Mutex
+	listeners     map[string]func(string)
+	notifications map[string]int64
+	fileTypes     map[string]string
+	cancel        context.CancelFunc
+}
+
+type configResult struct {
+	AppId          string            `json:"appId"`
+	Cluster        string            `json:"cluster"`
+	NamespaceName  string            `json:"namespaceName"`
+	Configurations map[string]string `json:"configurations"`
+	ReleaseKey     string            `json:"releaseKey"`
+}
+
+type notification struct {
+	NamespaceName  string `json:"namespaceName"`
+	NotificationId int64  `json:"notificationId"`
+}
+
+var (
+	configClient = &ConfigClient{}
+
+	ErrConfigNotFound = errors.New("config is not found")
+)
+
+func InitConfig(cfg *constants.Apollo) {
+	if utils.IsEmpty(cfg.AppId) {
+		cfg.AppId = cfg.ServiceName
+	}
+	if utils.IsEmpty(cfg.Cluster) {
+		cfg.Cluster = defaultCluster
+	}
+	if utils.IsEmpty(cfg.Env) {
+		cfg.Env = defaultEnv
+	}
+	if utils.IsEmpty(cfg.Operator) {
+		cfg.Operator = defaultOperator
+	}
+	cfg.ConfigHost = strings.TrimSuffix(cfg.ConfigHost, "/")
+	cfg.PortalHost = strings.TrimSuffix(cfg.PortalHost, "/")
+	configClient.cfg = cfg
+	configClient.client = &http.Client{Timeout: pollTimeout}
+	configClient.listeners = make(map[string]func(string))
+	configClient.notifications = make(map[string]int64)
+	configClient.fileTypes = make(map[string]string)
+	log.Println("[INFO] Init apollo config client successfully")
+}
+
+func GetConfigClient() *ConfigClient {
+	return configClient
+}
+
+// GetConfig 读取配置, 配置中心不可用时回退到本地缓存
+func (c *ConfigClient) GetConfig(dataId string) (string, error) {
+	namespace := c.getNamespace(dataId)
+	content, err := c.fetchConfig(namespace)
+	if utils.HasErr(err) {
+		if cached, cacheErr := c.readCache(namespace); !utils.HasErr(cacheErr) {
+			log.Printf("[WARNING] read apollo namespace %s err: %+v, fallback to local cache\n", namespace, err)
+			return cached, nil
+		}
+		return "", err
+	}
+	c.writeCache(namespace, content)
+	return content, nil
+}
+
+func (c *ConfigClient) PublishConfig(dataId, content string) error {
+	namespace := c.getNamespace(dataId)
+	if c.getFormat(namespace) == formatProps {
+		return fmt.Errorf("publishing properties namespace %s is not supported", namespace)
+	}
+	if utils.IsEmpty(c.cfg.PortalHost) {
+		return errors.New("apollo portal host is required for publishing")
+	}
+	base := c.openApiPath(namespace)
+	var result interface{}
+	err := curl.Request(base+"/items/"+contentKey+"?createIfNotExists=true", http.MethodPut, map[string]interface{}{
+		"key":                      contentKey,
+		"value":                    content,
+		"dataChangeLastModifiedBy": c.cfg.Operator,
+		"dataChangeCreatedBy":      c.cfg.Operator,
+	}, &result, c.openApiWrapper)
+	if utils.HasErr(err) {
+		return err
+	}
+	return curl.Request(base+"/releases", http.MethodPost, map[string]interface{}{
+		"releaseTitle": fmt.Sprintf("%s-%s", namespace, utils.SerialTimeStr()),
+		"releasedBy":   c.cfg.Operator,
+	}, &result, c.openApiWrapper)
+}
+
+func (c *ConfigClient) ListenConfig(dataId string, reload func(string)) error {
+	namespace := c.getNamespace(dataId)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners[namespace] = reload
+	if _, ok := c.notifications[namespace]; !ok {
+		c.notifications[namespace] = -1
+	}
+	if utils.IsEmpty(c.cancel) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		go c.poll(ctx)
+	}
+	return nil
+}
+
+func (c *ConfigClient) CancelListenConfig(dataId string) error {
+	namespace := c.getNamespace(dataId)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.listeners, namespace)
+	delete(c.notifications, namespace)
+	if utils.IsEmpty(c.listeners) && !utils.IsEmpty(c.cancel) {
+		c.cancel()
+		c.cancel = nil
+	}
+	return nil
+}
+
+// 长轮询通知接口, 有变更时重新拉取对应namespace并回调
+func (c *ConfigClient) poll(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		changed, err := c.fetchNotifications(ctx)
+		if utils.HasErr(err) {
+			if ctx.Err() == nil {
+				log.Printf("[ERROR] poll apollo notifications err: %+v\n", err)
+				time.Sleep(retryInterval)
+			}
+			continue
+		}
+		for _, n := range changed {
+			c.mu.Lock()
+			reload, ok := c.listeners[n.NamespaceName]
+			if ok {
+				c.notifications[n.NamespaceName] = n.NotificationId
+			}
+			c.mu.Unlock()
+			if !ok {
+				continue
+			}
+			content, err := c.fetchConfig(n.NamespaceName)
+			if utils.HasErr(err) {
+				log.Printf("[ERROR] reload apollo namespace %s err: %+v\n", n.NamespaceName, err)
+				continue
+			}
+			if cached, _ := c.readCache(n.NamespaceName); cached == content {
+				continue
+			}
+			c.writeCache(n.NamespaceName, content)
+			reload(content)
+		}
+	}
+}
+
+func (c *ConfigClient) fetchNotifications(ctx context.Context) ([]notification, error) {
+	c.mu.Lock()
+	var current []notification
+	for namespace, id := range c.notifications {
+		current = append(current, notification{namespace, id})
+	}
+	c.mu.Unlock()
+	query := url.Values{}
+	query.Set("appId", c.cfg.AppId)
+	query.Set("cluster", c.cfg.Cluster)
+	query.Set("notifications", string(utils.EncodeJsonValue(current)))
+	var changed []notification
+	status, err := c.get(ctx, "/notifications/v2", query, &changed)
+	if utils.HasErr(err) || status == http.StatusNotModified {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func (c *ConfigClient) fetchConfig(namespace string) (string, error) {
+	var result configResult
+	path := fmt.Sprintf("/configs/%s/%s/%s", url.PathEscape(c.cfg.AppId), url.PathEscape(c.cfg.Cluster), url.PathEscape(namespace))
+	status, err := c.get(context.Background(), path, url.Values{}, &result)
+	if utils.HasErr(err) {
+		return "", err
+	}
+	if status == http.StatusNotFound {
+		return "", ErrConfigNotFound
+	}
+	if c.getFormat(namespace) == formatProps {
+		return string(utils.EncodeJsonValue(result.Configurations)), nil
+	}
+	content := result.Configurations[contentKey]
+	if utils.IsEmpty(content) {
+		return "", ErrConfigNotFound
+	}
+	return content, nil
+}
+
+func (c *ConfigClient) get(ctx context.Context, path string, query url.Values, result interface{}) (int, error) {
+	pathWithQuery := path
+	if !utils.IsEmpty(query) {
+		pathWithQuery += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.ConfigHost+pathWithQuery, nil)
+	if utils.HasErr(err) {
+		return 0, err
+	}
+	c.sign(request, pathWithQuery)
+	response, err := c.client.Do(request)
+	if utils.HasErr(err) {
+		return 0, err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		body, err := ioutil.ReadAll(response.Body)
+		if utils.HasErr(err) {
+			return 0, err
+		}
+		return response.StatusCode, json.Unmarshal(body, result)
+	case http.StatusNotModified, http.StatusNotFound:
+		return response.StatusCode, nil
+	default:
+		return response.StatusCode, fmt.Errorf("request error with status %d", response.StatusCode)
+	}
+}
+
+// 开启访问密钥时需要签名: base64(HmacSHA1(secret, timestamp + "\n" + pathWithQuery))
+func (c *ConfigClient) sign(request *http.Request, pathWithQuery string) {
+	if utils.IsEmpty(c.cfg.Secret) {
+		return
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	mac := hmac.New(sha1.New, []byte(c.cfg.Secret))
+	mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	request.Header.Set("Authorization", fmt.Sprintf("Apollo %s:%s", c.cfg.AppId, signature))
+	request.Header.Set("Timestamp", timestamp)
+}
+
+func (c *ConfigClient) openApiPath(namespace string) string {
+	return fmt.Sprintf("%s/openapi/v1/envs/%s/apps/%s/clusters/%s/namespaces/%s", c.cfg.PortalHost,
+		url.PathEscape(c.cfg.Env), url.PathEscape(c.cfg.AppId), url.PathEscape(c.cfg.Cluster), url.PathEscape(namespace))
+}
+
+func (c *ConfigClient) openApiWrapper(r *http.Request) {
+	r.Header.Set("Authorization", c.cfg.Token)
+}
+
+// SetFileType 记录配置的文件类型, 用于生成namespace的后缀
+func (c *ConfigClient) SetFileType(dataId, fileType string) {
+	c.mu.Lock()
+	c.fileTypes[dataId] = strings.ToLower(fileType)
+	c.mu.Unlock()
+}
+
+// 配置名映射namespace, 未显式指定时按 <name>.<文件类型> 生成, 未记录文件类型时使用format
+// format为properties时namespace即配置名, 内容转为json
+func (c *ConfigClient) getNamespace(dataId string) string {
+	if namespace, ok := c.cfg.Namespaces[dataId]; ok && !utils.IsEmpty(namespace) {
+		return namespace
+	}
+	if c.cfg.Format == formatProps {
+		return dataId
+	}
+	c.mu.Lock()
+	format := c.fileTypes[dataId]
+	c.mu.Unlock()
+	format = utils.Or(format, utils.Or(c.cfg.Format, "json")).(string)
+	return dataId + "." + format
+}
+
+func (c *ConfigClient) getFormat(namespace string) string {
+	idx := strings.LastIndex(namespace, ".")
+	if idx < 0 {
+		return formatProps
+	}
+	return namespace[idx+1:]
+}
+
+func (c *ConfigClient) cacheFile(namespace string) string {
+	return fmt.Sprintf("%scache/%s+%s+%s", c.cfg.TempPath, c.cfg.AppId, c.cfg.Cluster, namespace)
+}
+
+func (c *ConfigClient) readCache(namespace string) (string, error) {
+	b, err := ioutil.ReadFile(c.cacheFile(namespace))
+	if utils.HasErr(err) {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (c *ConfigClient) writeCache(namespace, content string) {
+	if err := os.MkdirAll(c.cfg.TempPath+"cache", os.ModePerm); utils.HasErr(err) {
+		log.Printf("[WARNING] create apollo cache dir err: %+v\n", err)
+		return
+	}
+	if err := ioutil.WriteFile(c.cacheFile(namespace), []byte(content), 0644); utils.HasErr(err) {
+		log.Printf("[WARNING] write apollo cache %s err: %+v\n", namespace, err)
+	}
+}
@@ -0,0 +1,172 @@
+package apollo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+)
+
+// fakeServer 模拟apollo配置服务, content为demo.json的当前内容, changed置为true后通知接口返回一次变更
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	content  string
+	down     bool
+	changed  bool
+	notified int64
+}
+
+func newFakeServer(t *testing.T, content string) *fakeServer {
+	s := &fakeServer{content: content}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	down, content, changed := s.down, s.content, s.changed
+	if r.URL.Path == "/notifications/v2" {
+		s.changed = false
+	}
+	s.mu.Unlock()
+	if down {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch r.URL.Path {
+	case "/configs/app/default/demo.json":
+		_ = json.NewEncoder(w).Encode(configResult{
+			AppId:          "app",
+			Cluster:        "default",
+			NamespaceName:  "demo.json",
+			Configurations: map[string]string{contentKey: content},
+		})
+	case "/notifications/v2":
+		if !changed {
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt64(&s.notified, 1)
+		_ = json.NewEncoder(w).Encode([]notification{{NamespaceName: "demo.json", NotificationId: 2}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *fakeServer) set(fn func(s *fakeServer)) {
+	s.mu.Lock()
+	fn(s)
+	s.mu.Unlock()
+}
+
+func newTestClient(t *testing.T, host string) *ConfigClient {
+	InitConfig(&constants.Apollo{
+		ConfigHost:  host + "/",
+		AppId:       "app",
+		TempPath:    t.TempDir() + "/",
+		ServiceName: "app",
+	})
+	c := GetConfigClient()
+	c.SetFileType("demo", "json")
+	return c
+}
+
+func TestGetConfigFallbackToCache(t *testing.T) {
+	s := newFakeServer(t, `{"port":8000}`)
+	c := newTestClient(t, s.URL)
+	content, err := c.GetConfig("demo")
+	if err != nil || content != `{"port":8000}` {
+		t.Fatalf("GetConfig() = %q, %v", content, err)
+	}
+	s.set(func(s *fakeServer) { s.down = true })
+	content, err = c.GetConfig("demo")
+	if err != nil || content != `{"port":8000}` {
+		t.Fatalf("GetConfig() with server down = %q, %v, want cached content", content, err)
+	}
+	if _, err = c.GetConfig("other"); err == nil {
+		t.Fatal("GetConfig() without cache should fail when server is down")
+	}
+}
+
+func TestListenConfigReload(t *testing.T) {
+	s := newFakeServer(t, `{"port":8000}`)
+	c := newTestClient(t, s.URL)
+	if _, err := c.GetConfig("demo"); err != nil {
+		t.Fatalf("GetConfig() err: %v", err)
+	}
+	reloaded := make(chan string, 1)
+	if err := c.ListenConfig("demo", func(content string) { reloaded <- content }); err != nil {
+		t.Fatalf("ListenConfig() err: %v", err)
+	}
+	defer func() { _ = c.CancelListenConfig("demo") }()
+	s.set(func(s *fakeServer) {
+		s.content = `{"port":9000}`
+		s.changed = true
+	})
+	select {
+	case content := <-reloaded:
+		if content != `{"port":9000}` {
+			t.Fatalf("reload content = %q", content)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("reload is not called after notification")
+	}
+	if n := atomic.LoadInt64(&s.notified); n != 1 {
+		t.Fatalf("notified %d times, want 1", n)
+	}
+	c.mu.Lock()
+	id := c.notifications["demo.json"]
+	c.mu.Unlock()
+	if id != 2 {
+		t.Fatalf("notification id = %d, want 2", id)
+	}
+}
+
+func TestSign(t *testing.T) {
+	c := &ConfigClient{cfg: &constants.Apollo{AppId: "app", Secret: "secret"}}
+	request := httptest.NewRequest(http.MethodGet, "/configs/app/default/demo.json", nil)
+	c.sign(request, "/configs/app/default/demo.json")
+	timestamp := request.Header.Get("Timestamp")
+	if timestamp == "" {
+		t.Fatal("Timestamp header is not set")
+	}
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "\n/configs/app/default/demo.json"))
+	want := "Apollo app:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if got := request.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+	unsigned := httptest.NewRequest(http.MethodGet, "/", nil)
+	(&ConfigClient{cfg: &constants.Apollo{AppId: "app"}}).sign(unsigned, "/")
+	if unsigned.Header.Get("Authorization") != "" {
+		t.Fatal("request should not be signed without secret")
+	}
+}
+
+func TestGetNamespace(t *testing.T) {
+	c := &ConfigClient{
+		cfg:       &constants.Apollo{Namespaces: map[string]string{"db": "database"}},
+		fileTypes: map[string]string{"server": "yaml"},
+	}
+	cases := map[string]string{"server": "server.yaml", "cache": "cache.json", "db": "database"}
+	for dataId, want := range cases {
+		if got := c.getNamespace(dataId); got != want {
+			t.Errorf("getNamespace(%q) = %q, want %q", dataId, got, want)
+		}
+	}
+	c.cfg.Format = formatProps
+	if got := c.getNamespace("server"); got != "server" {
+		t.Errorf("getNamespace() with properties format = %q, want server", got)
+	}
+}
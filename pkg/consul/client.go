@@ -0,0 +1,87 @@
+package consul
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// client 基于consul HTTP API
+type client struct {
+	cfg  *constants.Consul
+	http *http.Client
+	// watchHttp 阻塞查询最长等待watchWait, 超时需大于等待时间
+	watchHttp *http.Client
+}
+
+// newClient 复制配置后规范化地址及前缀, 不修改调用方的配置
+func newClient(cfg *constants.Consul) *client {
+	c := *cfg
+	c.Host = strings.TrimSuffix(cfg.Host, "/")
+	c.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &client{cfg: &c, http: &http.Client{Timeout: requestTimeout}, watchHttp: &http.Client{Timeout: watchTimeout}}
+}
+
+// do 发送请求, 状态码非2xx及404时返回错误, 调用方负责关闭Body
+func (c *client) do(ctx context.Context, hc *http.Client, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	if utils.IsEmpty(query) {
+		query = url.Values{}
+	}
+	if !utils.IsEmpty(c.cfg.Datacenter) {
+		query.Set("dc", c.cfg.Datacenter)
+	}
+	target := c.cfg.Host + path
+	if !utils.IsEmpty(query) {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if !utils.IsEmpty(body) {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if !utils.IsEmpty(c.cfg.Token) {
+		request.Header.Set("X-Consul-Token", c.cfg.Token)
+	}
+	response, err := hc.Do(request)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusBadRequest && response.StatusCode != http.StatusNotFound {
+		msg, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		return nil, fmt.Errorf("consul request %s error with status %d: %s", path, response.StatusCode, msg)
+	}
+	return response, nil
+}
+
+func (c *client) put(path string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	response, err := c.do(ctx, c.http, http.MethodPut, path, nil, body)
+	if utils.HasErr(err) {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("consul request %s error with status %d", path, response.StatusCode)
+	}
+	return nil
+}
+
+func (c *client) key(elem ...string) string {
+	if !utils.IsEmpty(c.cfg.Prefix) {
+		elem = append([]string{c.cfg.Prefix}, elem...)
+	}
+	return strings.Join(elem, "/")
+}
@@ -0,0 +1,155 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	watchWait      = 5 * time.Minute
+	// watchTimeout consul会在等待时间上增加最多1/16的随机抖动
+	watchTimeout  = watchWait + watchWait/16 + requestTimeout
+	retryInterval = time.Second
+)
+
+type ConfigClient struct {
+	*client
+	mu       sync.Mutex
+	watchers map[string]context.CancelFunc
+}
+
+var (
+	configClient = &ConfigClient{}
+
+	ErrConfigNotFound = errors.New("config is not found")
+)
+
+func InitConfig(cfg *constants.Consul) {
+	configClient.client = newClient(cfg)
+	configClient.watchers = make(map[string]context.CancelFunc)
+	log.Println("[INFO] Init consul config client successfully")
+}
+
+func GetConfigClient() *ConfigClient {
+	return configClient
+}
+
+// GetConfig 优先读取 <prefix>/<service>/<dataId>, 不存在时读取公共配置 <prefix>/<dataId>
+func (c *ConfigClient) GetConfig(dataId string) (string, error) {
+	_, content, _, err := c.find(dataId)
+	return content, err
+}
+
+func (c *ConfigClient) PublishConfig(dataId, content string) error {
+	key, _, _, err := c.find(dataId)
+	if utils.HasErr(err) {
+		key = c.configKey(dataId)
+	}
+	return c.put("/v1/kv/"+key, []byte(content))
+}
+
+func (c *ConfigClient) ListenConfig(dataId string, reload func(string)) error {
+	key, content, index, err := c.find(dataId)
+	if utils.HasErr(err) {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	if stop, ok := c.watchers[dataId]; ok {
+		stop()
+	}
+	c.watchers[dataId] = cancel
+	c.mu.Unlock()
+	go c.watch(ctx, key, content, index, reload)
+	return nil
+}
+
+func (c *ConfigClient) CancelListenConfig(dataId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stop, ok := c.watchers[dataId]; ok {
+		stop()
+		delete(c.watchers, dataId)
+	}
+	return nil
+}
+
+// watch 通过阻塞查询监听key, 索引变化且内容变化时回调
+func (c *ConfigClient) watch(ctx context.Context, key, content string, index uint64, reload func(string)) {
+	for ctx.Err() == nil {
+		query := url.Values{}
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", watchWait.String())
+		next, found, latest, err := c.get(ctx, c.watchHttp, key, query)
+		if utils.HasErr(err) {
+			if ctx.Err() == nil {
+				log.Printf("[ERROR] watch consul key %s err: %+v\n", key, err)
+				time.Sleep(retryInterval)
+			}
+			continue
+		}
+		// 索引回退时需要重置
+		if next < index {
+			next = 0
+		}
+		index = next
+		if !found {
+			continue
+		}
+		if latest != content {
+			content = latest
+			reload(content)
+		}
+	}
+}
+
+func (c *ConfigClient) find(dataId string) (string, string, uint64, error) {
+	for _, key := range []string{c.configKey(dataId), c.key(dataId)} {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		index, found, content, err := c.get(ctx, c.http, key, nil)
+		cancel()
+		if utils.HasErr(err) {
+			return "", "", 0, err
+		}
+		if found {
+			return key, content, index, nil
+		}
+	}
+	return "", "", 0, ErrConfigNotFound
+}
+
+func (c *ConfigClient) get(ctx context.Context, hc *http.Client, key string, query url.Values) (uint64, bool, string, error) {
+	if utils.IsEmpty(query) {
+		query = url.Values{}
+	}
+	query.Set("raw", "")
+	response, err := c.do(ctx, hc, http.MethodGet, "/v1/kv/"+key, query, nil)
+	if utils.HasErr(err) {
+		return 0, false, "", err
+	}
+	defer response.Body.Close()
+	index, _ := strconv.ParseUint(response.Header.Get("X-Consul-Index"), 10, 64)
+	if response.StatusCode == http.StatusNotFound {
+		return index, false, "", nil
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if utils.HasErr(err) {
+		return 0, false, "", err
+	}
+	return index, true, string(body), nil
+}
+
+func (c *ConfigClient) configKey(dataId string) string {
+	return c.key(c.cfg.ServiceName, dataId)
+}
@@ -0,0 +1,110 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+type NamingClient struct {
+	*client
+	mu        sync.Mutex
+	instances map[int]*instance
+}
+
+type instance struct {
+	id       string
+	register []byte
+	cancel   context.CancelFunc
+}
+
+var namingClient = &NamingClient{}
+
+func InitNaming(cfg *constants.Consul) {
+	namingClient.client = newClient(cfg)
+	namingClient.instances = make(map[int]*instance)
+	log.Println("[INFO] Init consul naming client successfully")
+}
+
+func GetNamingClient() *NamingClient {
+	return namingClient
+}
+
+// RegisterInstance 注册带TTL检查的服务实例, 并定期上报健康状态
+func (c *NamingClient) RegisterInstance(port int, weight float64, meta map[string]string) error {
+	ip := utils.GetLocalIP()
+	id := fmt.Sprintf("%s-%s-%d", c.cfg.ServiceName, ip, port)
+	ttl := time.Duration(c.cfg.TTL) * time.Second
+	ins := &instance{
+		id: id,
+		register: utils.EncodeJsonValue(map[string]interface{}{
+			"ID":      id,
+			"Name":    c.cfg.ServiceName,
+			"Address": ip,
+			"Port":    port,
+			"Meta":    meta,
+			"Weights": map[string]int{"Passing": int(weight), "Warning": 1},
+			"Check": map[string]string{
+				"TTL":                            ttl.String(),
+				"DeregisterCriticalServiceAfter": (ttl * 6).String(),
+			},
+		}),
+	}
+	if err := c.put("/v1/agent/service/register", ins.register); utils.HasErr(err) {
+		return err
+	}
+	if err := c.pass(ins); utils.HasErr(err) {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+	c.mu.Lock()
+	c.instances[port] = ins
+	c.mu.Unlock()
+	go c.keepAlive(ctx, ins, ttl)
+	return nil
+}
+
+func (c *NamingClient) UnregisterInstance(port int) error {
+	c.mu.Lock()
+	ins, ok := c.instances[port]
+	delete(c.instances, port)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	ins.cancel()
+	return c.put("/v1/agent/service/deregister/"+ins.id, nil)
+}
+
+// keepAlive 每1/3个TTL上报一次, 实例被剔除时重新注册
+func (c *NamingClient) keepAlive(ctx context.Context, ins *instance, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.pass(ins); !utils.HasErr(err) {
+			continue
+		}
+		if err := c.put("/v1/agent/service/register", ins.register); utils.HasErr(err) {
+			log.Printf("[ERROR] register expired consul instance %s err: %+v\n", ins.id, err)
+			continue
+		}
+		if err := c.pass(ins); utils.HasErr(err) {
+			log.Printf("[ERROR] pass consul check of instance %s err: %+v\n", ins.id, err)
+		}
+	}
+}
+
+func (c *NamingClient) pass(ins *instance) error {
+	return c.put("/v1/agent/check/pass/service:"+ins.id, nil)
+}
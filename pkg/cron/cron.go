@@ -1,8 +1,13 @@
 package cron
 
 import (
+	"runtime/debug"
+	"time"
+
 	"github.com/robfig/cron/v3"
 
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
@@ -13,6 +18,7 @@ type Cron struct {
 
 type Job struct {
 	id      cron.EntryID
+	name    string
 	period  string
 	handler func()
 	preload bool
@@ -27,12 +33,24 @@ func NewCron() *Cron {
 	return c
 }
 
+// AddJob 以执行周期作为任务名称添加任务
 func (c *Cron) AddJob(period string, handler func(), preload bool) (cron.EntryID, error) {
-	id, err := c.cron.AddFunc(period, handler)
+	return c.AddNamedJob(period, period, func() error {
+		handler()
+		return nil
+	}, preload)
+}
+
+// AddNamedJob 添加任务, 按名称记录执行次数, 失败次数及耗时, 返回错误或panic均视为失败
+func (c *Cron) AddNamedJob(name, period string, handler func() error, preload bool) (cron.EntryID, error) {
+	run := func() {
+		runJob(name, handler)
+	}
+	id, err := c.cron.AddFunc(period, run)
 	if utils.HasErr(err) {
 		return 0, err
 	}
-	c.Jobs = append(c.Jobs, &Job{id, period, handler, preload})
+	c.Jobs = append(c.Jobs, &Job{id, name, period, run, preload})
 	return id, nil
 }
 
@@ -66,3 +84,19 @@ func (c *Cron) Start() {
 func (c *Cron) Stop() {
 	c.cron.Stop()
 }
+
+func runJob(name string, handler func() error) {
+	start := time.Now()
+	metrics.CronRuns.WithLabelValues(name).Inc()
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.CronFailures.WithLabelValues(name).Inc()
+			logging.With(logging.F("stack", string(debug.Stack()))).Error("Cron job %s panic: %v", name, r)
+		}
+		metrics.CronDuration.WithLabelValues(name).Observe(metrics.Since(start))
+	}()
+	if err := handler(); utils.HasErr(err) {
+		metrics.CronFailures.WithLabelValues(name).Inc()
+		logging.Error("Cron job %s err: %+v", name, err)
+	}
+}
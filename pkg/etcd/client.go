@@ -0,0 +1,163 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const requestTimeout = 10 * time.Second
+
+// client 基于etcd v3 grpc-gateway的JSON接口
+type client struct {
+	cfg  *constants.Etcd
+	http *http.Client
+	// watchHttp 监听为长连接, 不设置整体超时, 由ctx取消
+	watchHttp *http.Client
+	mu        sync.Mutex
+	token     string
+}
+
+type keyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+type responseHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+var ErrNoAvailableHost = errors.New("no available etcd host")
+
+// newClient 复制配置后规范化地址及前缀, 不修改调用方的配置
+func newClient(cfg *constants.Etcd) *client {
+	c := *cfg
+	c.Hosts = make([]string, len(cfg.Hosts))
+	for i, host := range cfg.Hosts {
+		c.Hosts[i] = strings.TrimSuffix(host, "/")
+	}
+	c.Prefix = strings.TrimSuffix(cfg.Prefix, "/")
+	return &client{cfg: &c, http: &http.Client{Timeout: requestTimeout}, watchHttp: &http.Client{}}
+}
+
+func (c *client) call(path string, req, res interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	response, err := c.do(ctx, c.http, path, req)
+	if utils.HasErr(err) {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if utils.HasErr(err) {
+		return err
+	}
+	if utils.IsEmpty(res) {
+		return nil
+	}
+	return json.Unmarshal(body, res)
+}
+
+// do 依次尝试各节点, 返回第一个成功的响应, 调用方负责关闭Body
+func (c *client) do(ctx context.Context, hc *http.Client, path string, req interface{}) (*http.Response, error) {
+	body := utils.EncodeJsonValue(req)
+	err := ErrNoAvailableHost
+	for _, host := range c.cfg.Hosts {
+		var response *http.Response
+		response, err = c.post(ctx, hc, host, path, body)
+		if utils.HasErr(err) {
+			continue
+		}
+		if response.StatusCode == http.StatusUnauthorized && !utils.IsEmpty(c.cfg.Username) {
+			response.Body.Close()
+			c.setToken("")
+			response, err = c.post(ctx, hc, host, path, body)
+			if utils.HasErr(err) {
+				continue
+			}
+		}
+		if response.StatusCode == http.StatusOK {
+			return response, nil
+		}
+		msg, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		err = fmt.Errorf("etcd request %s error with status %d: %s", path, response.StatusCode, msg)
+	}
+	return nil, err
+}
+
+func (c *client) post(ctx context.Context, hc *http.Client, host, path string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, host+path, bytes.NewReader(body))
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if !utils.IsEmpty(c.cfg.Username) {
+		token, err := c.getToken(ctx, host)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		request.Header.Set("Authorization", token)
+	}
+	return hc.Do(request)
+}
+
+func (c *client) getToken(ctx context.Context, host string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !utils.IsEmpty(c.token) {
+		return c.token, nil
+	}
+	body := utils.EncodeJsonValue(map[string]string{"name": c.cfg.Username, "password": c.cfg.Password})
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, host+"/v3/auth/authenticate", bytes.NewReader(body))
+	if utils.HasErr(err) {
+		return "", err
+	}
+	response, err := c.http.Do(request)
+	if utils.HasErr(err) {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd authenticate error with status %d", response.StatusCode)
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&result); utils.HasErr(err) {
+		return "", err
+	}
+	c.token = result.Token
+	return c.token, nil
+}
+
+func (c *client) setToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+func (c *client) key(elem ...string) string {
+	return c.cfg.Prefix + "/" + strings.Join(elem, "/")
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func decode(s string) string {
+	b, _ := base64.StdEncoding.DecodeString(s)
+	return string(b)
+}
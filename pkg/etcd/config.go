@@ -0,0 +1,157 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const retryInterval = time.Second
+
+type ConfigClient struct {
+	*client
+	mu       sync.Mutex
+	watchers map[string]context.CancelFunc
+}
+
+type watchResponse struct {
+	Result struct {
+		Header   responseHeader `json:"header"`
+		Canceled bool           `json:"canceled"`
+		Events   []struct {
+			Type string   `json:"type"`
+			Kv   keyValue `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+var (
+	configClient = &ConfigClient{}
+
+	ErrConfigNotFound = errors.New("config is not found")
+)
+
+func InitConfig(cfg *constants.Etcd) {
+	configClient.client = newClient(cfg)
+	configClient.watchers = make(map[string]context.CancelFunc)
+	log.Println("[INFO] Init etcd config client successfully")
+}
+
+func GetConfigClient() *ConfigClient {
+	return configClient
+}
+
+// GetConfig 优先读取 <prefix>/config/<service>/<dataId>, 不存在时读取公共配置 <prefix>/config/<dataId>
+func (c *ConfigClient) GetConfig(dataId string) (string, error) {
+	kv, err := c.getKeyValue(dataId)
+	if utils.HasErr(err) {
+		return "", err
+	}
+	return decode(kv.Value), nil
+}
+
+func (c *ConfigClient) PublishConfig(dataId, content string) error {
+	key := c.configKey(dataId)
+	if kv, err := c.getKeyValue(dataId); !utils.HasErr(err) {
+		key = decode(kv.Key)
+	}
+	return c.call("/v3/kv/put", map[string]string{"key": encode(key), "value": encode(content)}, nil)
+}
+
+func (c *ConfigClient) ListenConfig(dataId string, reload func(string)) error {
+	kv, err := c.getKeyValue(dataId)
+	if utils.HasErr(err) {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	if stop, ok := c.watchers[dataId]; ok {
+		stop()
+	}
+	c.watchers[dataId] = cancel
+	c.mu.Unlock()
+	go c.watch(ctx, decode(kv.Key), kv.ModRevision+1, reload)
+	return nil
+}
+
+func (c *ConfigClient) CancelListenConfig(dataId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stop, ok := c.watchers[dataId]; ok {
+		stop()
+		delete(c.watchers, dataId)
+	}
+	return nil
+}
+
+// watch 监听key的变更, 连接断开后从最后处理的版本继续监听
+func (c *ConfigClient) watch(ctx context.Context, key string, revision int64, reload func(string)) {
+	for ctx.Err() == nil {
+		next, err := c.watchOnce(ctx, key, revision, reload)
+		revision = next
+		if utils.HasErr(err) && ctx.Err() == nil {
+			log.Printf("[ERROR] watch etcd key %s err: %+v\n", key, err)
+			time.Sleep(retryInterval)
+		}
+	}
+}
+
+func (c *ConfigClient) watchOnce(ctx context.Context, key string, revision int64, reload func(string)) (int64, error) {
+	req := map[string]interface{}{
+		"create_request": map[string]interface{}{"key": encode(key), "start_revision": revision},
+	}
+	response, err := c.do(ctx, c.watchHttp, "/v3/watch", req)
+	if utils.HasErr(err) {
+		return revision, err
+	}
+	defer response.Body.Close()
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var res watchResponse
+		if err = decoder.Decode(&res); utils.HasErr(err) {
+			return revision, err
+		}
+		if !utils.IsEmpty(res.Error) {
+			return revision, errors.New(res.Error.Message)
+		}
+		if res.Result.Canceled {
+			return revision, errors.New("watch canceled by server")
+		}
+		for _, event := range res.Result.Events {
+			revision = event.Kv.ModRevision + 1
+			if event.Type == "DELETE" {
+				log.Printf("[WARNING] etcd key %s is deleted, ignore it\n", key)
+				continue
+			}
+			reload(decode(event.Kv.Value))
+		}
+	}
+}
+
+func (c *ConfigClient) getKeyValue(dataId string) (*keyValue, error) {
+	for _, key := range []string{c.configKey(dataId), c.key("config", dataId)} {
+		var res struct {
+			Kvs []*keyValue `json:"kvs"`
+		}
+		if err := c.call("/v3/kv/range", map[string]string{"key": encode(key)}, &res); utils.HasErr(err) {
+			return nil, err
+		}
+		if !utils.IsEmpty(res.Kvs) {
+			return res.Kvs[0], nil
+		}
+	}
+	return nil, ErrConfigNotFound
+}
+
+func (c *ConfigClient) configKey(dataId string) string {
+	return c.key("config", c.cfg.ServiceName, dataId)
+}
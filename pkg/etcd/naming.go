@@ -0,0 +1,118 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+type NamingClient struct {
+	*client
+	mu        sync.Mutex
+	instances map[int]*instance
+}
+
+// instance lease仅在注册及续约协程中修改, 注销时先等待协程退出再读取
+type instance struct {
+	key    string
+	value  string
+	lease  int64
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var namingClient = &NamingClient{}
+
+func InitNaming(cfg *constants.Etcd) {
+	namingClient.client = newClient(cfg)
+	namingClient.instances = make(map[int]*instance)
+	log.Println("[INFO] Init etcd naming client successfully")
+}
+
+func GetNamingClient() *NamingClient {
+	return namingClient
+}
+
+// RegisterInstance 以租约形式写入 <prefix>/services/<service>/<ip>:<port>, 并定期续约
+func (c *NamingClient) RegisterInstance(port int, weight float64, meta map[string]string) error {
+	ip := utils.GetLocalIP()
+	ins := &instance{
+		key: c.key("services", c.cfg.ServiceName, fmt.Sprintf("%s:%d", ip, port)),
+		value: string(utils.EncodeJsonValue(map[string]interface{}{
+			"ip":       ip,
+			"port":     port,
+			"weight":   weight,
+			"metadata": meta,
+		})),
+	}
+	if err := c.register(ins); utils.HasErr(err) {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ins.cancel = cancel
+	ins.done = make(chan struct{})
+	c.mu.Lock()
+	c.instances[port] = ins
+	c.mu.Unlock()
+	go c.keepAlive(ctx, ins)
+	return nil
+}
+
+func (c *NamingClient) UnregisterInstance(port int) error {
+	c.mu.Lock()
+	ins, ok := c.instances[port]
+	delete(c.instances, port)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	ins.cancel()
+	<-ins.done
+	return c.call("/v3/lease/revoke", map[string]interface{}{"ID": ins.lease}, nil)
+}
+
+func (c *NamingClient) register(ins *instance) error {
+	var lease struct {
+		ID int64 `json:"ID,string"`
+	}
+	if err := c.call("/v3/lease/grant", map[string]interface{}{"TTL": c.cfg.TTL}, &lease); utils.HasErr(err) {
+		return err
+	}
+	ins.lease = lease.ID
+	return c.call("/v3/kv/put", map[string]interface{}{"key": encode(ins.key), "value": encode(ins.value), "lease": ins.lease}, nil)
+}
+
+// keepAlive 每1/3个TTL续约一次, 租约过期时重新注册
+func (c *NamingClient) keepAlive(ctx context.Context, ins *instance) {
+	defer close(ins.done)
+	ticker := time.NewTicker(time.Duration(c.cfg.TTL) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var res struct {
+			Result struct {
+				TTL int64 `json:"TTL,string"`
+			} `json:"result"`
+		}
+		err := c.call("/v3/lease/keepalive", map[string]interface{}{"ID": ins.lease}, &res)
+		if !utils.HasErr(err) && res.Result.TTL > 0 {
+			continue
+		}
+		if utils.HasErr(err) {
+			log.Printf("[ERROR] keep alive etcd lease %d err: %+v\n", ins.lease, err)
+			continue
+		}
+		if err = c.register(ins); utils.HasErr(err) {
+			log.Printf("[ERROR] register expired etcd instance %s err: %+v\n", ins.key, err)
+		}
+	}
+}
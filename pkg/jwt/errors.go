@@ -0,0 +1,36 @@
+package jwt
+
+import "errors"
+
+// TokenError 令牌本身不合法, 应答401, 其余错误(如吊销列表不可用)属于服务端异常
+type TokenError struct {
+	reason string
+}
+
+func (e *TokenError) Error() string {
+	return e.reason
+}
+
+var (
+	ErrInvalidToken     = &TokenError{"token is invalid"}
+	ErrMalformedToken   = &TokenError{"token is malformed"}
+	ErrInvalidSignature = &TokenError{"token signature is invalid"}
+	ErrUnknownKey       = &TokenError{"token signing key is not found"}
+	ErrExpiredToken     = &TokenError{"token is expired"}
+	ErrNotValidYet      = &TokenError{"token is not valid yet"}
+	ErrInvalidIssuer    = &TokenError{"token issuer is invalid"}
+	ErrInvalidAudience  = &TokenError{"token audience is invalid"}
+	ErrTokenType        = &TokenError{"token type mismatch"}
+	ErrRevokedToken     = &TokenError{"token has been revoked"}
+)
+
+var (
+	ErrMissingSecret = errors.New("jwt secret, keys or resolver is required")
+	ErrNoSigningKey  = errors.New("no signing key is configured")
+)
+
+// IsTokenError 判断是否为令牌校验失败
+func IsTokenError(err error) bool {
+	var e *TokenError
+	return errors.As(err, &e)
+}
@@ -0,0 +1,233 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/curl"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// JWK RFC 7517公钥, 支持RSA, EC及OKP(Ed25519)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+func NewJWK(key *Key) (*JWK, error) {
+	jwk := &JWK{Kid: key.Id, Use: "sig", Alg: key.Algorithm}
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBase64(pub.N.Bytes())
+		jwk.E = encodeBase64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encodeBase64(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeBase64(pub)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.Public)
+	}
+	return jwk, nil
+}
+
+// Key 转换为仅用于验签的密钥
+func (k *JWK) Key() (*Key, error) {
+	var public crypto.PublicKey
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64(k.N)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		e, err := decodeBase64(k.E)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		curve, ok := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBase64(k.X)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		y, err := decodeBase64(k.Y)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		public = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "OKP":
+		x, err := decodeBase64(k.X)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		if k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		public = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+	return NewKey(k.Kid, k.Alg, nil, public)
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// jwksMinInterval 两次获取JWKS的最小间隔, 签发服务不可用或遇到伪造kid时避免持续请求
+const jwksMinInterval = 30 * time.Second
+
+// jwksFetchTimeout 单次获取JWKS的超时, 与请求上下文无关, 由等待中的请求共享结果
+const jwksFetchTimeout = 5 * time.Second
+
+// JwksClient 从签发服务获取并缓存JWKS, 缓存过期或遇到未知kid时刷新, 刷新失败时继续使用旧缓存
+type JwksClient struct {
+	url       string
+	ttl       time.Duration
+	mu        sync.Mutex
+	keys      *KeySet
+	lastErr   error
+	fetchedAt time.Time
+	triedAt   time.Time
+	call      *jwksCall
+}
+
+// jwksCall 进行中的获取, 并发请求等待同一次结果
+type jwksCall struct {
+	done chan struct{}
+	err  error
+}
+
+func NewJwksClient(url string, ttl time.Duration) *JwksClient {
+	return &JwksClient{url: url, ttl: ttl}
+}
+
+func (c *JwksClient) ResolveKey(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	keys, err := c.getKeys(ctx, false)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if keys.Get(kid) == nil {
+		if keys, err = c.getKeys(ctx, true); utils.HasErr(err) {
+			return nil, err
+		}
+	}
+	return keys.ResolveKey(ctx, kid, alg)
+}
+
+// getKeys 获取在锁外进行, 距上次获取不足jwksMinInterval时直接返回缓存, 无缓存时返回上次的错误
+func (c *JwksClient) getKeys(ctx context.Context, force bool) (*KeySet, error) {
+	c.mu.Lock()
+	call := c.call
+	if call == nil {
+		now := time.Now()
+		fresh := c.keys != nil && !force && now.Sub(c.fetchedAt) < c.ttl
+		if fresh || now.Sub(c.triedAt) < jwksMinInterval {
+			keys, err := c.keys, c.lastErr
+			c.mu.Unlock()
+			return cachedKeys(keys, err)
+		}
+		call = &jwksCall{done: make(chan struct{})}
+		c.call = call
+		c.triedAt = now
+		go c.refresh(call)
+	}
+	c.mu.Unlock()
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	c.mu.Lock()
+	keys := c.keys
+	c.mu.Unlock()
+	return cachedKeys(keys, call.err)
+}
+
+func (c *JwksClient) refresh(call *jwksCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := c.fetch(ctx)
+	c.mu.Lock()
+	if utils.HasErr(err) {
+		c.lastErr = err
+		if c.keys != nil {
+			logging.Warning("Fetch jwks from %s err, use cached keys: %+v", c.url, err)
+		}
+	} else {
+		c.keys = keys
+		c.lastErr = nil
+		c.fetchedAt = time.Now()
+	}
+	call.err = err
+	c.call = nil
+	c.mu.Unlock()
+	close(call.done)
+}
+
+func cachedKeys(keys *KeySet, err error) (*KeySet, error) {
+	if keys != nil {
+		return keys, nil
+	}
+	if !utils.HasErr(err) {
+		err = errors.New("jwks is not fetched yet")
+	}
+	return nil, err
+}
+
+func (c *JwksClient) fetch(ctx context.Context) (*KeySet, error) {
+	jwks := &JWKS{}
+	if err := curl.RequestWithContext(ctx, c.url, http.MethodGet, nil, jwks); utils.HasErr(err) {
+		return nil, err
+	}
+	if utils.IsEmpty(jwks.Keys) {
+		return nil, fmt.Errorf("jwks from %s is empty", c.url)
+	}
+	keys := NewKeySet()
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.Key()
+		if utils.HasErr(err) {
+			logging.Ctx(ctx).Warning("Skip jwk %s: %+v", jwk.Kid, err)
+			continue
+		}
+		keys.Add(key)
+	}
+	return keys, nil
+}
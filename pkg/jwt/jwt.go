@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -12,13 +13,63 @@ import (
 type Claims struct {
 	constants.UserBrief
 	jwt.StandardClaims
+	// Audience 覆盖StandardClaims中的aud, 兼容外部签发方使用的数组形式
+	Audience  Audience `json:"aud,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	// Custom 自定义声明原始内容, 使用Bind转换为具体类型
+	Custom json.RawMessage `json:"custom,omitempty"`
 	secret []byte
 }
 
+// Audience aud声明, 解码时兼容字符串及字符串数组, 仅有一个受众时编码为字符串
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = nil
+		if !utils.IsEmpty(s) {
+			*a = Audience{s}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); utils.HasErr(err) {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// Contains 任一受众在list中
+func (a Audience) Contains(list []string) bool {
+	for _, aud := range a {
+		if utils.InSlice(aud, list) {
+			return true
+		}
+	}
+	return false
+}
+
 func NewJwt(secret string) Claims {
 	return Claims{secret: []byte(secret)}
 }
 
+// Bind 将自定义声明解析到out, 未携带自定义声明时不做处理
+func (c *Claims) Bind(out interface{}) error {
+	if utils.IsEmpty(c.Custom) {
+		return nil
+	}
+	return json.Unmarshal(c.Custom, out)
+}
+
 func (c *Claims) SetExpired(expired time.Duration) {
 	c.ExpiresAt = utils.LocalTime().Add(expired).Unix()
 }
@@ -29,6 +80,7 @@ func (c Claims) GenerateTokenWithUsername(uid int, username string) (string, err
 	return jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(c.secret)
 }
 
+// GetUserBriefFromToken 仅校验签名及有效期, 需要校验签发者, 受众或读取自定义声明时使用Manager
 func (c Claims) GetUserBriefFromToken(tokenString string) (int, string, error) {
 	_, err := jwt.ParseWithClaims(tokenString, &c, func(token *jwt.Token) (interface{}, error) {
 		return c.secret, nil
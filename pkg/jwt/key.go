@@ -0,0 +1,258 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const AlgEdDSA = "EdDSA"
+
+// SigningMethodEdDSA Ed25519签名, jwt-go未内置
+var SigningMethodEdDSA = &signingMethodEd25519{}
+
+type signingMethodEd25519 struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(AlgEdDSA, func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEd25519) Alg() string {
+	return AlgEdDSA
+}
+
+func (m *signingMethodEd25519) Verify(signingString, signature string, key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if utils.HasErr(err) {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+func (m *signingMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(priv, []byte(signingString))), nil
+}
+
+// Key 非对称密钥, Private为空时仅用于验签
+type Key struct {
+	Id        string
+	Algorithm string
+	Method    jwt.SigningMethod
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+}
+
+// LoadKey 从PEM文件加载私钥或公钥, 支持PKCS1/PKCS8/SEC1私钥及PKIX公钥
+func LoadKey(kid, alg, path string) (*Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	return ParseKey(kid, alg, data)
+}
+
+func ParseKey(kid, alg string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not in PEM format", kid)
+	}
+	var private crypto.PrivateKey
+	var public crypto.PublicKey
+	var err error
+	switch {
+	case strings.HasSuffix(block.Type, "PUBLIC KEY"):
+		public, err = parsePublicKey(block.Bytes)
+	case block.Type == "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); !utils.HasErr(err) {
+			public = cert.PublicKey
+		}
+	default:
+		private, err = parsePrivateKey(block.Bytes)
+		if signer, ok := private.(crypto.Signer); ok {
+			public = signer.Public()
+		}
+	}
+	if utils.HasErr(err) {
+		return nil, fmt.Errorf("parse key %s err: %w", kid, err)
+	}
+	return NewKey(kid, alg, private, public)
+}
+
+// NewKey 校验算法与密钥类型是否匹配
+func NewKey(kid, alg string, private crypto.PrivateKey, public crypto.PublicKey) (*Key, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	if !matchAlgorithm(alg, public) {
+		return nil, fmt.Errorf("key %s does not match algorithm %s", kid, alg)
+	}
+	return &Key{Id: kid, Algorithm: alg, Method: method, Private: private, Public: public}, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); !utils.HasErr(err) {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); !utils.HasErr(err) {
+		return key, nil
+	}
+	return x509.ParseECPrivateKey(der)
+}
+
+func parsePublicKey(der []byte) (crypto.PublicKey, error) {
+	if key, err := x509.ParsePKIXPublicKey(der); !utils.HasErr(err) {
+		return key, nil
+	}
+	return x509.ParsePKCS1PublicKey(der)
+}
+
+func matchAlgorithm(alg string, public crypto.PublicKey) bool {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return curveAlgorithm(key.Curve) == alg
+	case ed25519.PublicKey:
+		return alg == AlgEdDSA
+	}
+	return false
+}
+
+func curveAlgorithm(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "ES256"
+	case elliptic.P384():
+		return "ES384"
+	case elliptic.P521():
+		return "ES512"
+	}
+	return ""
+}
+
+// KeyResolver 按kid及算法查找验签公钥
+type KeyResolver interface {
+	ResolveKey(ctx context.Context, kid, alg string) (crypto.PublicKey, error)
+}
+
+// KeySet 按kid管理密钥, 轮换时先加入新密钥并切换签名密钥, 旧密钥保留至其签发的令牌全部过期后再移除
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	order   []string
+	signing string
+}
+
+// NewKeySet 第一个包含私钥的密钥作为签名密钥
+func NewKeySet(keys ...*Key) *KeySet {
+	s := &KeySet{keys: make(map[string]*Key)}
+	for _, key := range keys {
+		s.Add(key)
+	}
+	return s
+}
+
+func (s *KeySet) Add(key *Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[key.Id]; !ok {
+		s.order = append(s.order, key.Id)
+	}
+	s.keys[key.Id] = key
+	if utils.IsEmpty(s.signing) && key.Private != nil {
+		s.signing = key.Id
+	}
+}
+
+func (s *KeySet) Remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, kid)
+	for i, id := range s.order {
+		if id == kid {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	if s.signing == kid {
+		s.signing = ""
+	}
+}
+
+func (s *KeySet) SetSigningKey(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[kid]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if key.Private == nil {
+		return fmt.Errorf("key %s has no private key", kid)
+	}
+	s.signing = kid
+	return nil
+}
+
+func (s *KeySet) SigningKey() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[s.signing]
+}
+
+func (s *KeySet) Get(kid string) *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[kid]
+}
+
+func (s *KeySet) ResolveKey(_ context.Context, kid, alg string) (crypto.PublicKey, error) {
+	key := s.Get(kid)
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+	if key.Algorithm != alg {
+		return nil, ErrInvalidSignature
+	}
+	return key.Public, nil
+}
+
+// JWKS 导出全部公钥, 供验签服务获取
+func (s *KeySet) JWKS() *JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jwks := &JWKS{Keys: make([]*JWK, 0, len(s.order))}
+	for _, kid := range s.order {
+		if jwk, err := NewJWK(s.keys[kid]); !utils.HasErr(err) {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
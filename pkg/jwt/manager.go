@@ -0,0 +1,294 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+const (
+	DefaultAccessExpired  = 2 * time.Hour
+	DefaultRefreshExpired = 7 * 24 * time.Hour
+)
+
+type Option struct {
+	// Secret HS256共享密钥, 配置Keys时不再使用
+	Secret string
+	// Keys 非对称密钥, 使用其签名密钥签发并在头部写入kid
+	Keys *KeySet
+	// Resolver 验签公钥来源, 为空时使用Keys, 仅验签的服务可使用JwksClient
+	Resolver KeyResolver
+	// Issuer 签发时写入iss, 非空时校验iss一致
+	Issuer string
+	// Audience 签发时写入第一个作为aud, 非空时校验aud为其中之一
+	Audience []string
+	// Leeway 校验exp, nbf及iat时允许的时钟偏差
+	Leeway         time.Duration
+	AccessExpired  time.Duration
+	RefreshExpired time.Duration
+	// Revoker 吊销列表, 为空时无法注销令牌, 刷新令牌轮换后旧令牌在过期前仍可使用
+	Revoker Revoker
+}
+
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+
+// Manager 签发访问令牌及刷新令牌, 负责刷新轮换及吊销
+type Manager struct {
+	opt *Option
+}
+
+func NewManager(opt *Option) (*Manager, error) {
+	if utils.IsEmpty(opt.Secret) && opt.Keys == nil && opt.Resolver == nil {
+		return nil, ErrMissingSecret
+	}
+	if opt.Resolver == nil && opt.Keys != nil {
+		opt.Resolver = opt.Keys
+	}
+	if opt.AccessExpired <= 0 {
+		opt.AccessExpired = DefaultAccessExpired
+	}
+	if opt.RefreshExpired <= 0 {
+		opt.RefreshExpired = DefaultRefreshExpired
+	}
+	return &Manager{opt: opt}, nil
+}
+
+// Issue 签发一对新的访问令牌及刷新令牌
+func (m *Manager) Issue(user constants.UserBrief) (*TokenPair, error) {
+	return m.IssueWithClaims(user, nil)
+}
+
+// IssueWithClaims 签发时附带自定义声明(如角色, 租户, 权限范围), 刷新时原样保留, 解析后通过Claims.Bind读取
+func (m *Manager) IssueWithClaims(user constants.UserBrief, custom interface{}) (*TokenPair, error) {
+	var raw json.RawMessage
+	switch v := custom.(type) {
+	case nil:
+	case json.RawMessage:
+		raw = v
+	default:
+		b, err := json.Marshal(v)
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		raw = b
+	}
+	access, err := m.sign(user, raw, TokenTypeAccess, m.opt.AccessExpired)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	refresh, err := m.sign(user, raw, TokenTypeRefresh, m.opt.RefreshExpired)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		ExpiresIn:        int64(m.opt.AccessExpired / time.Second),
+		RefreshExpiresIn: int64(m.opt.RefreshExpired / time.Second),
+	}, nil
+}
+
+// Keys 签发服务的密钥, 用于对外提供JWKS
+func (m *Manager) Keys() *KeySet {
+	return m.opt.Keys
+}
+
+// ParseAccess 校验访问令牌, 已吊销的令牌返回ErrRevokedToken
+func (m *Manager) ParseAccess(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := m.parse(ctx, tokenString, TokenTypeAccess)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if err = m.checkRevoked(ctx, claims); utils.HasErr(err) {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌对, 旧刷新令牌随即吊销, 重复使用返回ErrRevokedToken
+func (m *Manager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := m.parse(ctx, refreshToken, TokenTypeRefresh)
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if m.opt.Revoker != nil {
+		revoked, err := m.opt.Revoker.Revoke(ctx, claims.Id, m.remaining(claims))
+		if utils.HasErr(err) {
+			return nil, err
+		}
+		if !revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+	return m.IssueWithClaims(claims.UserBrief, claims.Custom)
+}
+
+// Revoke 吊销令牌直至其过期, 用于注销, 已过期的令牌直接忽略
+func (m *Manager) Revoke(ctx context.Context, tokens ...string) error {
+	for _, tokenString := range tokens {
+		if utils.IsEmpty(tokenString) {
+			continue
+		}
+		claims, err := m.parse(ctx, tokenString, "")
+		if errors.Is(err, ErrExpiredToken) {
+			continue
+		}
+		if utils.HasErr(err) {
+			return err
+		}
+		if m.opt.Revoker == nil {
+			return errors.New("jwt revoker is not configured")
+		}
+		if _, err = m.opt.Revoker.Revoke(ctx, claims.Id, m.remaining(claims)); utils.HasErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) sign(user constants.UserBrief, custom json.RawMessage, tokenType string, expired time.Duration) (string, error) {
+	now := utils.LocalTime()
+	claims := Claims{
+		UserBrief: user,
+		StandardClaims: jwt.StandardClaims{
+			Id:        newTokenId(),
+			Issuer:    m.opt.Issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(expired).Unix(),
+		},
+		TokenType: tokenType,
+		Custom:    custom,
+	}
+	if !utils.IsEmpty(m.opt.Audience) {
+		claims.Audience = Audience{m.opt.Audience[0]}
+	}
+	if m.opt.Keys != nil {
+		key := m.opt.Keys.SigningKey()
+		if key == nil {
+			return "", ErrNoSigningKey
+		}
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.Id
+		return token.SignedString(key.Private)
+	}
+	if utils.IsEmpty(m.opt.Secret) {
+		return "", ErrNoSigningKey
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.opt.Secret))
+}
+
+// parse 校验签名及声明, tokenType为空时不校验令牌类型
+func (m *Manager) parse(ctx context.Context, tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// 配置公钥时只接受非对称算法, 避免以公钥作为HMAC密钥伪造令牌
+		if m.opt.Resolver != nil {
+			kid, _ := token.Header["kid"].(string)
+			return m.opt.Resolver.ResolveKey(ctx, kid, token.Method.Alg())
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidSignature
+		}
+		return []byte(m.opt.Secret), nil
+	})
+	if utils.HasErr(err) {
+		return nil, parseErr(err)
+	}
+	if err = m.validate(claims); utils.HasErr(err) {
+		return nil, err
+	}
+	if !utils.IsEmpty(tokenType) && claims.TokenType != tokenType {
+		return nil, ErrTokenType
+	}
+	if utils.IsEmpty(claims.Id) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parseErr 转换为TokenError, 获取JWKS失败等非令牌错误原样返回
+func parseErr(err error) error {
+	e, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return ErrInvalidToken
+	}
+	switch {
+	case e.Errors&jwt.ValidationErrorMalformed != 0:
+		return ErrMalformedToken
+	case e.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return ErrInvalidSignature
+	case e.Errors&jwt.ValidationErrorUnverifiable != 0 && e.Inner != nil:
+		return e.Inner
+	}
+	return ErrInvalidToken
+}
+
+// validate 按leeway校验有效期, 并校验签发者及受众, aud为数组时任一受众匹配即可
+func (m *Manager) validate(claims *Claims) error {
+	now := time.Now().Unix()
+	leeway := int64(m.opt.Leeway / time.Second)
+	if claims.ExpiresAt != 0 && now > claims.ExpiresAt+leeway {
+		return ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now+leeway < claims.NotBefore {
+		return ErrNotValidYet
+	}
+	if claims.IssuedAt != 0 && now+leeway < claims.IssuedAt {
+		return ErrNotValidYet
+	}
+	if !utils.IsEmpty(m.opt.Issuer) && claims.Issuer != m.opt.Issuer {
+		return ErrInvalidIssuer
+	}
+	if !utils.IsEmpty(m.opt.Audience) && !claims.Audience.Contains(m.opt.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func (m *Manager) checkRevoked(ctx context.Context, claims *Claims) error {
+	if m.opt.Revoker == nil {
+		return nil
+	}
+	revoked, err := m.opt.Revoker.IsRevoked(ctx, claims.Id)
+	if utils.HasErr(err) {
+		return err
+	}
+	if revoked {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// remaining 令牌剩余有效期, 校验时允许leeway的偏差, 吊销记录需保留至exp+leeway
+func (m *Manager) remaining(claims *Claims) time.Duration {
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0)) + m.opt.Leeway
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+func newTokenId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
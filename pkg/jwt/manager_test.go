@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/EvisuXiao/andrews-common/constants"
+)
+
+// memoryRevoker 记录吊销的jti及保留时长
+type memoryRevoker struct {
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+func newMemoryRevoker() *memoryRevoker {
+	return &memoryRevoker{ttls: make(map[string]time.Duration)}
+}
+
+func (r *memoryRevoker) Revoke(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ttls[id]; ok {
+		return false, nil
+	}
+	r.ttls[id] = ttl
+	return true, nil
+}
+
+func (r *memoryRevoker) IsRevoked(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.ttls[id]
+	return ok, nil
+}
+
+func newTestManager(t *testing.T, opt *Option) *Manager {
+	if opt.Secret == "" {
+		opt.Secret = "secret"
+	}
+	m, err := NewManager(opt)
+	if err != nil {
+		t.Fatalf("NewManager() err: %v", err)
+	}
+	return m
+}
+
+func TestRemainingIncludesLeeway(t *testing.T) {
+	m := newTestManager(t, &Option{Leeway: 30 * time.Second})
+	cases := []struct {
+		name string
+		exp  time.Duration
+		want time.Duration
+	}{
+		{"not expired", 10 * time.Second, 40 * time.Second},
+		{"expired within leeway", -10 * time.Second, 20 * time.Second},
+		{"expired beyond leeway", -time.Minute, time.Second},
+	}
+	for _, c := range cases {
+		claims := &Claims{StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(c.exp).Unix()}}
+		if got := m.remaining(claims); got < c.want-2*time.Second || got > c.want+time.Second {
+			t.Errorf("%s: remaining() = %s, want about %s", c.name, got, c.want)
+		}
+	}
+}
+
+// TestRefreshRevokesUntilLeewayEnds 轮换后的刷新令牌在leeway内仍可通过校验, 吊销记录需覆盖该时段
+func TestRefreshRevokesUntilLeewayEnds(t *testing.T) {
+	revoker := newMemoryRevoker()
+	m := newTestManager(t, &Option{Leeway: time.Minute, RefreshExpired: time.Hour, Revoker: revoker})
+	pair, err := m.Issue(constants.UserBrief{Uid: 1, Username: "test"})
+	if err != nil {
+		t.Fatalf("Issue() err: %v", err)
+	}
+	if _, err = m.Refresh(context.Background(), pair.RefreshToken); err != nil {
+		t.Fatalf("Refresh() err: %v", err)
+	}
+	if _, err = m.Refresh(context.Background(), pair.RefreshToken); err != ErrRevokedToken {
+		t.Fatalf("second Refresh() err = %v, want ErrRevokedToken", err)
+	}
+	for id, ttl := range revoker.ttls {
+		if ttl < time.Hour+time.Minute-2*time.Second {
+			t.Fatalf("revoked %s for %s, want at least refresh expiry plus leeway", id, ttl)
+		}
+	}
+}
+
+func signMapClaims(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign err: %v", err)
+	}
+	return token
+}
+
+func TestParseAudience(t *testing.T) {
+	m := newTestManager(t, &Option{Audience: []string{"api", "admin"}})
+	exp := time.Now().Add(time.Hour).Unix()
+	cases := []struct {
+		name string
+		aud  interface{}
+		err  error
+	}{
+		{"string", "api", nil},
+		{"array", []string{"web", "admin"}, nil},
+		{"array without match", []string{"web", "mobile"}, ErrInvalidAudience},
+		{"string without match", "web", ErrInvalidAudience},
+		{"missing", nil, ErrInvalidAudience},
+	}
+	for _, c := range cases {
+		claims := jwt.MapClaims{"jti": "id", "exp": exp, "uid": 1, "token_type": TokenTypeAccess}
+		if c.aud != nil {
+			claims["aud"] = c.aud
+		}
+		_, err := m.ParseAccess(context.Background(), signMapClaims(t, claims))
+		if err != c.err {
+			t.Errorf("%s: ParseAccess() err = %v, want %v", c.name, err, c.err)
+		}
+	}
+	pair, err := m.Issue(constants.UserBrief{Uid: 1})
+	if err != nil {
+		t.Fatalf("Issue() err: %v", err)
+	}
+	claims, err := m.ParseAccess(context.Background(), pair.AccessToken)
+	if err != nil || len(claims.Audience) != 1 || claims.Audience[0] != "api" {
+		t.Fatalf("ParseAccess() = %+v, %v, want aud api", claims, err)
+	}
+}
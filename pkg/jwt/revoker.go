@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"context"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/pkg/redis"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// Revoker 令牌吊销列表, 以jti为键
+type Revoker interface {
+	// Revoke 吊销令牌, 首次吊销返回true, 已吊销返回false
+	Revoke(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+const revokedKeyPrefix = "jwt:revoked:"
+
+type RedisRevoker struct {
+	client *redis.Client
+}
+
+func NewRedisRevoker(client *redis.Client) *RedisRevoker {
+	return &RedisRevoker{client: client}
+}
+
+func (r *RedisRevoker) Revoke(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.WithContext(ctx).SetNX(revokedKeyPrefix+id, 1, ttl)
+	return ok, utils.NormalizeErr(err)
+}
+
+// IsRevoked redis不可用时返回错误, 由调用方拒绝请求
+func (r *RedisRevoker) IsRevoked(ctx context.Context, id string) (bool, error) {
+	_, err := r.client.WithContext(ctx).GetString(revokedKeyPrefix + id)
+	err = utils.NormalizeErr(err)
+	if !utils.HasErr(err) {
+		return true, nil
+	}
+	if redis.IsNilErr(err) {
+		return false, nil
+	}
+	return false, err
+}
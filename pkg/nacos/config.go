@@ -2,6 +2,7 @@ package nacos
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/url"
 	"strconv"
@@ -27,22 +28,28 @@ var (
 	ErrConfigNotFound = errors.New("config is not found")
 )
 
-func InitConfig(cfg *constants.Nacos) {
-	var err error
-	configClient.client, err = clients.NewConfigClient(buildClientParam(cfg))
+// InitConfig 初始化配置客户端, 失败时返回错误由调用方决定是否退出
+func InitConfig(cfg *constants.Nacos) error {
+	param, err := buildClientParam(cfg)
 	if utils.HasErr(err) {
-		log.Fatalf("[FATAL] Init fatal: init nacos config client error: %+v\n", err)
+		return err
+	}
+	client, err := clients.NewConfigClient(param)
+	if utils.HasErr(err) {
+		return fmt.Errorf("init nacos config client error: %+v", err)
 	}
+	configClient.client = client
 	configClient.groupName = cfg.GroupName
 	configClient.serviceName = cfg.ServiceName
 	log.Println("[INFO] Init nacos config client successfully")
+	return nil
 }
 
 func GetConfigClient() *ConfigClient {
 	return configClient
 }
 
-func buildClientParam(cfg *constants.Nacos) vo.NacosClientParam {
+func buildClientParam(cfg *constants.Nacos) (vo.NacosClientParam, error) {
 	cCfg := constant.NewClientConfig(
 		constant.WithNamespaceId(cfg.Namespace),
 		constant.WithUsername(cfg.Username),
@@ -55,7 +62,7 @@ func buildClientParam(cfg *constants.Nacos) vo.NacosClientParam {
 	for _, host := range cfg.Hosts {
 		u, err := url.Parse(host)
 		if utils.HasErr(err) {
-			log.Fatalf("[FATAL] Init fatal: parse nacos host error: %+v\n", err)
+			return vo.NacosClientParam{}, fmt.Errorf("parse nacos host error: %+v", err)
 		}
 		p, _ := strconv.ParseUint(u.Port(), 10, 32)
 		if utils.IsEmpty(p) {
@@ -72,7 +79,7 @@ func buildClientParam(cfg *constants.Nacos) vo.NacosClientParam {
 	return vo.NacosClientParam{
 		ClientConfig:  cCfg,
 		ServerConfigs: sCfg,
-	}
+	}, nil
 }
 
 func (c *ConfigClient) GetConfig(dataId string) (string, error) {
@@ -1,7 +1,12 @@
 package nacos
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/nacos-group/nacos-sdk-go/clients"
 	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
@@ -13,20 +18,28 @@ import (
 
 type NamingClient struct {
 	client      naming_client.INamingClient
+	hosts       []string
 	groupName   string
 	serviceName string
 }
 
+// pingPath nacos服务端状态接口, 正常时返回 "status":"UP"
+const pingPath = "/nacos/v1/ns/operator/metrics"
+
 var (
 	namingClient = &NamingClient{}
 )
 
 func InitNaming(cfg *constants.Nacos) {
-	var err error
-	namingClient.client, err = clients.NewNamingClient(buildClientParam(cfg))
+	param, err := buildClientParam(cfg)
+	if utils.HasErr(err) {
+		log.Fatalf("[FATAL] Init fatal: %+v\n", err)
+	}
+	namingClient.client, err = clients.NewNamingClient(param)
 	if utils.HasErr(err) {
 		log.Fatalf("[FATAL] Init fatal: init nacos naming client error: %+v\n", err)
 	}
+	namingClient.hosts = cfg.Hosts
 	namingClient.groupName = cfg.GroupName
 	namingClient.serviceName = cfg.ServiceName
 	log.Println("[INFO] Init nacos naming client successfully")
@@ -36,6 +49,36 @@ func GetNamingClient() *NamingClient {
 	return namingClient
 }
 
+// Ping 任一nacos服务端可用即视为连接正常
+func (c *NamingClient) Ping(ctx context.Context) error {
+	var err error
+	for _, host := range c.hosts {
+		if err = pingHost(ctx, host); !utils.HasErr(err) {
+			return nil
+		}
+	}
+	if !utils.HasErr(err) {
+		err = errors.New("no nacos host is configured")
+	}
+	return err
+}
+
+func pingHost(ctx context.Context, host string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(host, "/")+pingPath, nil)
+	if utils.HasErr(err) {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if utils.HasErr(err) {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nacos %s responds with status %d", host, resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *NamingClient) RegisterInstance(port int, weight float64, meta map[string]string) error {
 	param := vo.RegisterInstanceParam{
 		Ip:          utils.GetLocalIP(),
@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []*Tag               `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 键为小写的请求方法
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationId string               `json:"operationId,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// OperationSpec 接口声明, Request及Response为对应类型的零值, 为空时不生成参数或响应结构
+type OperationSpec struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}
+	Response    interface{}
+	// Envelope 响应外层结构的组件名称, 响应类型作为其data字段, 为空时直接使用响应类型
+	Envelope string
+}
+
+// Generator 按请求及响应类型生成文档, 具名结构体生成为组件并按引用复用
+type Generator struct {
+	doc   *Document
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func NewGenerator(info Info) *Generator {
+	return &Generator{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      make(map[string]*PathItem),
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+}
+
+func (g *Generator) Document() *Document {
+	return g.doc
+}
+
+// AddTag 声明标签说明, 未声明的标签由Swagger UI按出现顺序展示
+func (g *Generator) AddTag(name, description string) {
+	g.doc.Tags = append(g.doc.Tags, &Tag{Name: name, Description: description})
+}
+
+// AddComponent 直接注册组件, 如统一的响应外层结构
+func (g *Generator) AddComponent(name string, v interface{}) {
+	g.doc.Components.Schemas[name] = g.structSchema(indirectType(reflect.TypeOf(v)))
+}
+
+// AddOperation 添加接口, 路径中的 :param 及 *param 转为 {param}
+func (g *Generator) AddOperation(spec *OperationSpec) {
+	path, pathParams := convertPath(spec.Path)
+	method := strings.ToLower(spec.Method)
+	op := &Operation{
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		OperationId: operationId(method, path),
+		Tags:        spec.Tags,
+		Responses:   map[string]*Response{"200": g.response(spec.Response, spec.Envelope)},
+	}
+	if spec.Request != nil {
+		g.request(op, reflect.TypeOf(spec.Request), hasBody(spec.Method))
+	}
+	// 请求类型中未声明的路径参数同样需要列出
+	for _, name := range pathParams {
+		if !hasParameter(op, name, "path") {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: TypeString}})
+		}
+	}
+	item, ok := g.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		g.doc.Paths[path] = item
+	}
+	(*item)[method] = op
+}
+
+// request 带uri, header标签的字段为路径及请求头参数, 有请求体的方法中json字段为请求体, 其余带form标签的字段为查询参数
+func (g *Generator) request(op *Operation, t reflect.Type, withBody bool) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		if withBody {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(g.Schema(t))}
+		}
+		return
+	}
+	body := &Schema{Type: TypeObject, Properties: make(map[string]*Schema)}
+	for _, f := range fields(t) {
+		schema := g.fieldSchema(f)
+		required := isRequired(f)
+		if name := tagName(f, "uri"); name != "" {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		} else if name = tagName(f, "header"); name != "" {
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "header", Required: required, Schema: schema})
+		} else if name = tagName(f, "json"); name != "" && withBody {
+			body.Properties[name] = schema
+			if required {
+				body.Required = append(body.Required, name)
+			}
+		} else if name = tagName(f, "form"); name != "" {
+			if withBody {
+				body.Properties[name] = schema
+				if required {
+					body.Required = append(body.Required, name)
+				}
+				continue
+			}
+			op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "query", Required: required, Schema: schema})
+		}
+	}
+	if len(body.Properties) > 0 {
+		op.RequestBody = &RequestBody{Required: len(body.Required) > 0, Content: jsonContent(body)}
+	}
+}
+
+func (g *Generator) response(v interface{}, envelope string) *Response {
+	resp := &Response{Description: http.StatusText(http.StatusOK)}
+	var data *Schema
+	if v != nil {
+		data = g.Schema(reflect.TypeOf(v))
+	}
+	if envelope == "" {
+		if data != nil {
+			resp.Content = jsonContent(data)
+		}
+		return resp
+	}
+	schema := Ref(envelope)
+	if data != nil {
+		schema = &Schema{AllOf: []*Schema{schema, {Type: TypeObject, Properties: map[string]*Schema{"data": data}}}}
+	}
+	resp.Content = jsonContent(schema)
+	return resp
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+func hasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func hasParameter(op *Operation, name, in string) bool {
+	for _, p := range op.Parameters {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// convertPath 转为OpenAPI路径格式并返回路径参数
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			params = append(params, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func operationId(method, path string) string {
+	replacer := strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_", ".", "_")
+	return method + strings.TrimRight(replacer.Replace(path), "_")
+}
@@ -0,0 +1,299 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+const refPrefix = "#/components/schemas/"
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+}
+
+func Ref(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	bytesType      = reflect.TypeOf([]byte{})
+)
+
+// Schema 按json编码结果生成结构, 具名结构体注册为组件后返回引用
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	t = indirectType(t)
+	switch t {
+	case timeType:
+		return &Schema{Type: TypeString, Format: "date-time"}
+	case durationType:
+		return &Schema{Type: TypeInteger, Format: "int64", Description: "nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	case bytesType:
+		return &Schema{Type: TypeString, Format: "byte"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: TypeInteger, Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: TypeInteger, Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: TypeNumber, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: TypeNumber, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: TypeArray, Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: TypeObject, AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return Ref(g.component(t))
+	}
+	return &Schema{}
+}
+
+// component 注册具名结构体, 不同包中的同名结构体以包名区分
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if other, ok := g.types[name]; ok && other != t {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	g.names[t] = name
+	g.types[name] = t
+	// 生成前已记录名称, 自引用的字段直接返回引用
+	g.doc.Components.Schemas[name] = g.structSchema(t)
+	return name
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: TypeObject, Properties: make(map[string]*Schema)}
+	for _, f := range fields(t) {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema.Properties[name] = g.fieldSchema(f)
+		if isRequired(f) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// fieldSchema 字段结构附加binding标签中的校验规则
+func (g *Generator) fieldSchema(f reflect.StructField) *Schema {
+	schema := g.Schema(f.Type)
+	applyRules(schema, strings.Split(f.Tag.Get("binding"), ","))
+	return schema
+}
+
+// applyRules 将validator规则转为结构约束, dive之后的规则作用于元素
+func applyRules(schema *Schema, rules []string) {
+	if schema.Ref != "" {
+		return
+	}
+	for i, rule := range rules {
+		if rule == "dive" {
+			if schema.Items != nil {
+				applyRules(schema.Items, rules[i+1:])
+			} else if schema.AdditionalProperties != nil {
+				applyRules(schema.AdditionalProperties, rules[i+1:])
+			}
+			return
+		}
+		// 或条件无法表示, 直接忽略
+		if strings.Contains(rule, "|") {
+			continue
+		}
+		kv := strings.SplitN(rule, "=", 2)
+		param := ""
+		if len(kv) > 1 {
+			param = kv[1]
+		}
+		applyRule(schema, kv[0], param)
+	}
+}
+
+func applyRule(schema *Schema, name, param string) {
+	switch name {
+	case "min", "gte":
+		setBound(schema, param, true, false)
+	case "max", "lte":
+		setBound(schema, param, false, false)
+	case "gt":
+		setBound(schema, param, true, true)
+	case "lt":
+		setBound(schema, param, false, true)
+	case "len", "eq":
+		setBound(schema, param, true, false)
+		setBound(schema, param, false, false)
+	case "oneof":
+		for _, v := range strings.Fields(param) {
+			schema.Enum = append(schema.Enum, enumValue(schema, v))
+		}
+	case "email":
+		schema.Format = "email"
+	case "url", "uri", "http_url":
+		schema.Format = "uri"
+	case "uuid", "uuid3", "uuid4", "uuid5":
+		schema.Format = "uuid"
+	case "ipv4":
+		schema.Format = "ipv4"
+	case "ipv6":
+		schema.Format = "ipv6"
+	case "datetime":
+		schema.Description = "layout: " + param
+	case "alpha":
+		schema.Pattern = "^[a-zA-Z]+$"
+	case "alphanum":
+		schema.Pattern = "^[a-zA-Z0-9]+$"
+	case "numeric":
+		schema.Pattern = "^[-+]?[0-9]+(?:\\.[0-9]+)?$"
+	}
+}
+
+// setBound 数值为取值范围, 字符串为长度, 数组为元素个数, exclusive时长度及个数按整数换算
+func setBound(schema *Schema, param string, lower, exclusive bool) {
+	switch schema.Type {
+	case TypeInteger, TypeNumber:
+		v, err := strconv.ParseFloat(param, 64)
+		if utils.HasErr(err) {
+			return
+		}
+		if lower {
+			schema.Minimum, schema.ExclusiveMinimum = &v, exclusive
+		} else {
+			schema.Maximum, schema.ExclusiveMaximum = &v, exclusive
+		}
+	case TypeString, TypeArray:
+		v, err := strconv.ParseUint(param, 10, 64)
+		if utils.HasErr(err) {
+			return
+		}
+		if exclusive && lower {
+			v++
+		} else if exclusive {
+			if v == 0 {
+				return
+			}
+			v--
+		}
+		switch {
+		case schema.Type == TypeString && lower:
+			schema.MinLength = &v
+		case schema.Type == TypeString:
+			schema.MaxLength = &v
+		case lower:
+			schema.MinItems = &v
+		default:
+			schema.MaxItems = &v
+		}
+	}
+}
+
+func enumValue(schema *Schema, v string) interface{} {
+	switch schema.Type {
+	case TypeInteger, TypeNumber:
+		if n, err := strconv.ParseFloat(v, 64); !utils.HasErr(err) {
+			return n
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(v); !utils.HasErr(err) {
+			return b
+		}
+	}
+	return v
+}
+
+// fields 导出字段, 未设置json标签的匿名结构体字段展开
+func fields(t reflect.Type) []reflect.StructField {
+	var result []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			result = append(result, fields(indirectType(f.Type))...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+func isRequired(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		if rule == "dive" {
+			return false
+		}
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName 标签中的名称, 未设置或为-时为空
+func tagName(f reflect.StructField, tag string) string {
+	name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/pkg/redis"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// Limit 每个period允许rate次请求, burst为允许的突发请求数, 为0时等于rate
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second, Burst: rate}
+}
+
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute, Burst: rate}
+}
+
+func PerHour(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Hour, Burst: rate}
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+type Result struct {
+	Limit   Limit
+	Allowed bool
+	// Remaining 当前还可通过的请求数
+	Remaining int
+	// RetryAfter 被拒绝时距离下次可通过的时间
+	RetryAfter time.Duration
+	// ResetAfter 距离额度完全恢复的时间
+	ResetAfter time.Duration
+}
+
+// gcraScript GCRA算法, 以redis服务端时间计算, 各副本共享同一限额
+// 时间减去2020-01-01的偏移量以保证浮点精度
+const gcraScript = `
+redis.replicate_commands()
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local interval = period / rate
+local increment = interval * cost
+local burst_offset = interval * burst
+local t = redis.call("TIME")
+local now = (tonumber(t[1]) - 1577836800) + tonumber(t[2]) / 1000000
+local tat = redis.call("GET", key)
+if tat then
+  tat = math.max(tonumber(tat), now)
+else
+  tat = now
+end
+local new_tat = tat + increment
+local diff = now - (new_tat - burst_offset)
+if diff < 0 then
+  return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+local reset_after = new_tat - now
+if reset_after > 0 then
+  redis.call("SET", key, new_tat, "EX", math.ceil(reset_after))
+end
+return {1, math.floor(diff / interval), "0", tostring(reset_after)}
+`
+
+var script = redis.NewScript(gcraScript)
+
+const keyPrefix = "ratelimit:"
+
+var ErrInvalidLimit = errors.New("rate limit rate and period must be positive")
+
+// Limiter 基于redis的分布式限流器
+type Limiter struct {
+	client *redis.Client
+}
+
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{client: client}
+}
+
+// Allow 判断key本次请求是否通过
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	if limit.Rate <= 0 || limit.Period <= 0 {
+		return nil, ErrInvalidLimit
+	}
+	values, err := l.client.WithContext(ctx).RunScript(script, []string{keyPrefix + key}, limit.burst(), limit.Rate, limit.Period.Seconds(), 1).Slice()
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, errors.New("unexpected rate limit script result")
+	}
+	retryAfter, err := parseSeconds(values[2])
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	resetAfter, err := parseSeconds(values[3])
+	if utils.HasErr(err) {
+		return nil, err
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	return &Result{
+		Limit:      limit,
+		Allowed:    allowed == 1,
+		Remaining:  int(remaining),
+		RetryAfter: retryAfter,
+		ResetAfter: resetAfter,
+	}, nil
+}
+
+func parseSeconds(v interface{}) (time.Duration, error) {
+	s, _ := v.(string)
+	f, err := strconv.ParseFloat(s, 64)
+	if utils.HasErr(err) {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
@@ -0,0 +1,109 @@
+package rbac
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/EvisuXiao/andrews-common/database"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+func CreateRole(name, description string) (*Role, error) {
+	role := &Role{Name: name, Description: description}
+	_, err := RoleModel.AddRow(role)
+	return role, err
+}
+
+func CreatePermission(code, description string) (*Permission, error) {
+	perm := &Permission{Code: code, Description: description}
+	_, err := PermissionModel.AddRow(perm)
+	return perm, err
+}
+
+// DeleteRole 在事务中删除角色及其权限绑定和用户关联, 提交后再清除相关用户的权限缓存
+func DeleteRole(ctx context.Context, roleId int64) error {
+	var uids []int
+	err := RoleModel.GetDb().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(UserRoleModel.TableName()).Where("role_id = ?", roleId).Distinct("uid").Pluck("uid", &uids).Error
+		if utils.HasErr(err) {
+			return err
+		}
+		for _, table := range []string{UserRoleModel.TableName(), RolePermissionModel.TableName()} {
+			if err = tx.Table(table).Where("role_id = ?", roleId).Delete(nil).Error; utils.HasErr(err) {
+				return err
+			}
+		}
+		return tx.Table(RoleModel.TableName()).Where(RoleModel.GetPk()+" = ?", roleId).Delete(nil).Error
+	})
+	if utils.HasErr(err) {
+		return dbErr(err)
+	}
+	InvalidateUser(ctx, uids...)
+	return nil
+}
+
+// GrantPermissions 为角色绑定权限, 已绑定的忽略
+func GrantPermissions(ctx context.Context, roleId int64, permissionIds ...int64) error {
+	if utils.IsEmpty(permissionIds) {
+		return nil
+	}
+	rows := make([]*RolePermission, 0, len(permissionIds))
+	for _, id := range permissionIds {
+		rows = append(rows, &RolePermission{RoleId: roleId, PermissionId: id})
+	}
+	if err := RolePermissionModel.GetDb().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error; utils.HasErr(err) {
+		return dbErr(err)
+	}
+	return dbErr(InvalidateRole(ctx, roleId))
+}
+
+func RevokePermissions(ctx context.Context, roleId int64, permissionIds ...int64) error {
+	conditions := database.NewSingleEqConditions("role_id", roleId)
+	conditions.AddCondition("permission_id", database.OP_IN, permissionIds)
+	if err := dbErr(RolePermissionModel.DeleteRows(conditions)); utils.HasErr(err) {
+		return err
+	}
+	return dbErr(InvalidateRole(ctx, roleId))
+}
+
+// AssignRoles 为用户分配角色, 已分配的忽略
+func AssignRoles(ctx context.Context, uid int, roleIds ...int64) error {
+	if utils.IsEmpty(roleIds) {
+		return nil
+	}
+	rows := make([]*UserRole, 0, len(roleIds))
+	for _, id := range roleIds {
+		rows = append(rows, &UserRole{Uid: int64(uid), RoleId: id})
+	}
+	if err := UserRoleModel.GetDb().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error; utils.HasErr(err) {
+		return dbErr(err)
+	}
+	InvalidateUser(ctx, uid)
+	return nil
+}
+
+func UnassignRoles(ctx context.Context, uid int, roleIds ...int64) error {
+	conditions := database.NewSingleEqConditions("uid", uid)
+	conditions.AddCondition("role_id", database.OP_IN, roleIds)
+	if err := dbErr(UserRoleModel.DeleteRows(conditions)); utils.HasErr(err) {
+		return err
+	}
+	InvalidateUser(ctx, uid)
+	return nil
+}
+
+func GetUserRoles(uid int) ([]*Role, error) {
+	var roleIds []int64
+	err := UserRoleModel.GetDb().Table(UserRoleModel.TableName()).Where("uid = ?", uid).Pluck("role_id", &roleIds).Error
+	if utils.HasErr(err) {
+		return nil, dbErr(err)
+	}
+	var roles []*Role
+	if utils.IsEmpty(roleIds) {
+		return roles, nil
+	}
+	err = dbErr(RoleModel.GetAnyRowsByIds(roleIds, nil, &roles))
+	return roles, err
+}
@@ -0,0 +1,67 @@
+package rbac
+
+import (
+	"github.com/EvisuXiao/andrews-common/database"
+)
+
+// Model rbac数据表挂载到Option.Database指定的数据库
+type Model struct {
+	database.Model
+}
+
+func (m *Model) MountDb() {
+	m.SetDatabaseByName(option.Database)
+}
+
+type Role struct {
+	Model
+	Name        string `gorm:"size:64;uniqueIndex" json:"name"`
+	Description string `gorm:"size:255" json:"description"`
+}
+
+// Permission 权限码格式为 资源:操作, 如order:write, 以*结尾时按前缀匹配, 如order:*
+type Permission struct {
+	Model
+	Code        string `gorm:"size:128;uniqueIndex" json:"code"`
+	Description string `gorm:"size:255" json:"description"`
+}
+
+type RolePermission struct {
+	Model
+	RoleId       int64 `gorm:"uniqueIndex:idx_role_permission" json:"role_id"`
+	PermissionId int64 `gorm:"uniqueIndex:idx_role_permission" json:"permission_id"`
+}
+
+type UserRole struct {
+	Model
+	Uid    int64 `gorm:"uniqueIndex:idx_user_role" json:"uid"`
+	RoleId int64 `gorm:"uniqueIndex:idx_user_role;index" json:"role_id"`
+}
+
+var (
+	RoleModel           = &Role{}
+	PermissionModel     = &Permission{}
+	RolePermissionModel = &RolePermission{}
+	UserRoleModel       = &UserRole{}
+)
+
+func (*Role) TableName() string {
+	return "rbac_roles"
+}
+
+func (*Permission) TableName() string {
+	return "rbac_permissions"
+}
+
+func (*RolePermission) TableName() string {
+	return "rbac_role_permissions"
+}
+
+func (*UserRole) TableName() string {
+	return "rbac_user_roles"
+}
+
+// Migrate 创建或更新rbac数据表
+func Migrate() error {
+	return RoleModel.GetDb().AutoMigrate(&Role{}, &Permission{}, &RolePermission{}, &UserRole{})
+}
@@ -0,0 +1,157 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/database"
+	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/redis"
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	DefaultCacheExpired = 10 * time.Minute
+	cacheKeyPrefix      = "rbac:permissions:"
+)
+
+type Option struct {
+	// Database rbac数据表所在的数据库标识名
+	Database string
+	// Redis 缓存用户权限集合的连接名称, 为空时每次查询数据库
+	Redis        string
+	CacheExpired time.Duration
+}
+
+var option = &Option{}
+
+// Init 注册rbac数据模型, 需在database.Init之前调用
+func Init(opt *Option) {
+	if utils.IsEmpty(opt.Database) {
+		logging.Fatal("Init rbac fatal: database is required")
+	}
+	if opt.CacheExpired <= 0 {
+		opt.CacheExpired = DefaultCacheExpired
+	}
+	option = opt
+	database.RegisterModel(RoleModel)
+	database.RegisterModel(PermissionModel)
+	database.RegisterModel(RolePermissionModel)
+	database.RegisterModel(UserRoleModel)
+}
+
+// GetUserPermissions 获取用户全部权限码, 优先读取缓存
+func GetUserPermissions(ctx context.Context, uid int) ([]string, error) {
+	client := cacheClient(ctx)
+	key := cacheKey(uid)
+	var perms []string
+	if client != nil {
+		err := client.GetScan(key, &perms)
+		if !utils.HasErr(err) {
+			return perms, nil
+		}
+		if !redis.IsNilErr(err) {
+			logging.Ctx(ctx).Warning("Get rbac permissions cache err: %+v", err)
+		}
+	}
+	err := RoleModel.GetDb().WithContext(ctx).
+		Table(PermissionModel.TableName()+" AS p").
+		Distinct("p.code").
+		Joins("JOIN "+RolePermissionModel.TableName()+" AS rp ON rp.permission_id = p.id").
+		Joins("JOIN "+UserRoleModel.TableName()+" AS ur ON ur.role_id = rp.role_id").
+		Where("ur.uid = ?", uid).
+		Pluck("p.code", &perms).Error
+	if utils.HasErr(err) {
+		return nil, dbErr(err)
+	}
+	sort.Strings(perms)
+	if client != nil {
+		if perms == nil {
+			perms = []string{}
+		}
+		if err = dbErr(client.Set(key, perms, option.CacheExpired)); utils.HasErr(err) {
+			logging.Ctx(ctx).Warning("Set rbac permissions cache err: %+v", err)
+		}
+	}
+	return perms, nil
+}
+
+// HasPermission 用户拥有全部所需权限时返回true
+func HasPermission(ctx context.Context, uid int, required ...string) (bool, error) {
+	perms, err := GetUserPermissions(ctx, uid)
+	if utils.HasErr(err) {
+		return false, err
+	}
+	for _, r := range required {
+		if !matchAny(perms, r) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchAny(perms []string, required string) bool {
+	for _, p := range perms {
+		if p == required || strings.HasSuffix(p, "*") && strings.HasPrefix(required, strings.TrimSuffix(p, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateUser 清除用户权限缓存, 用户角色变更后调用
+func InvalidateUser(ctx context.Context, uids ...int) {
+	client := cacheClient(ctx)
+	if client == nil {
+		return
+	}
+	for _, uid := range uids {
+		if err := dbErr(client.Delete(cacheKey(uid))); utils.HasErr(err) {
+			logging.Ctx(ctx).Warning("Delete rbac permissions cache err: %+v", err)
+		}
+	}
+}
+
+// InvalidateRole 清除拥有该角色的全部用户的权限缓存, 角色权限变更后调用
+func InvalidateRole(ctx context.Context, roleIds ...int64) error {
+	if cacheClient(ctx) == nil {
+		return nil
+	}
+	var uids []int
+	err := UserRoleModel.GetDb().WithContext(ctx).Table(UserRoleModel.TableName()).
+		Where("role_id IN ?", roleIds).Distinct("uid").Pluck("uid", &uids).Error
+	if utils.HasErr(err) {
+		return err
+	}
+	InvalidateUser(ctx, uids...)
+	return nil
+}
+
+func cacheClient(ctx context.Context) *redis.Client {
+	if utils.IsEmpty(option.Redis) {
+		return nil
+	}
+	client := redis.GetRedis(option.Redis)
+	if client == nil {
+		logging.Ctx(ctx).Warning("Rbac cache redis(%s) is not registered", option.Redis)
+		return nil
+	}
+	return client.WithContext(ctx)
+}
+
+func cacheKey(uid int) string {
+	return fmt.Sprintf("%s%d", cacheKeyPrefix, uid)
+}
+
+// dbErr 包装数据库及缓存错误, 成功时返回的nil *DbError转为nil以便判断
+func dbErr(err error) error {
+	err = utils.NormalizeErr(err)
+	if _, ok := err.(*exception.DbError); ok || !utils.HasErr(err) {
+		return err
+	}
+	return exception.DbErrWrapper(err)
+}
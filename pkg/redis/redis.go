@@ -10,6 +10,7 @@ import (
 
 	"github.com/EvisuXiao/andrews-common/config"
 	"github.com/EvisuXiao/andrews-common/exception"
+	"github.com/EvisuXiao/andrews-common/health"
 	"github.com/EvisuXiao/andrews-common/logging"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
@@ -39,6 +40,16 @@ func RegisterRedis(c *Client) {
 	clients = append(clients, c)
 }
 
+// GetRedis 按连接名称获取已注册的客户端, 未注册时返回nil
+func GetRedis(name string) *Client {
+	for _, c := range clients {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
 func (c *Client) setup() {
 	cnf, ok := config.GetCacheConfigs().Redis[c.Name]
 	if !ok {
@@ -54,13 +65,15 @@ func (c *Client) setup() {
 	default:
 		logging.Fatal("Init: unknown redis driver: %s", cnf.Driver)
 	}
+	c.instrument()
+	if utils.IsEmpty(c.Ctx) {
+		c.Ctx = context.Background()
+	}
 	pong, err := c.redis.Ping(c.Ctx).Result()
 	if utils.HasErr(err) {
 		logging.Fatal("Setup: redis(%s) connection failed: %+v", c.Name, err)
 	}
-	if utils.IsEmpty(c.Ctx) {
-		c.Ctx = context.Background()
-	}
+	health.RegisterChecker("redis:"+c.Name, c.Ping)
 	logging.Info("Redis setup(%s) successfully: %s", c.Name, pong)
 }
 
@@ -97,6 +110,19 @@ func (c *Client) connClusterClient(cnf *config.Redis) {
 	c.prefix = cnf.Prefix
 }
 
+func (c *Client) Ping(ctx context.Context) error {
+	return c.GetClient().Ping(ctx).Err()
+}
+
+// WithContext 返回使用指定上下文的副本, 便于传递请求的超时及链路
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	if ctx != nil {
+		cc.Ctx = ctx
+	}
+	return &cc
+}
+
 func (c *Client) GetClient() redis.Cmdable {
 	return c.redis
 }
@@ -147,6 +173,22 @@ func (c *Client) Delete(key string) error {
 	return exception.DbErrWrapper(c.GetClient().Del(c.Ctx, c.getCacheKey(key)).Err())
 }
 
+// Script lua脚本, 使用EVALSHA执行, 脚本未缓存时自动回退到EVAL
+type Script = redis.Script
+
+func NewScript(src string) *Script {
+	return redis.NewScript(src)
+}
+
+// RunScript 执行脚本, keys自动添加连接前缀
+func (c *Client) RunScript(script *Script, keys []string, args ...interface{}) *redis.Cmd {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.getCacheKey(key)
+	}
+	return script.Run(c.Ctx, c.GetClient(), prefixed, args...)
+}
+
 func (c *Client) getCmd(key string) *redis.StringCmd {
 	return c.GetClient().Get(c.Ctx, c.getCacheKey(key))
 }
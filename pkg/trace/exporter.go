@@ -0,0 +1,270 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+)
+
+const (
+	batchSize     = 100
+	queueSize     = 2048
+	flushInterval = time.Second
+	otlpScopeName = "github.com/EvisuXiao/andrews-common"
+)
+
+// Option exporter为空时只传播链路不导出
+type Option struct {
+	Exporter   string
+	Endpoint   string
+	SampleRate float64
+	Service    string
+}
+
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// StdoutExporter 每行输出一个span的json
+type StdoutExporter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// OtlpExporter 以OTLP/HTTP json格式发送, 如 http://127.0.0.1:4318/v1/traces
+type OtlpExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+}
+
+type processor struct {
+	exporter Exporter
+	queue    chan *Span
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+var (
+	rate   = 1.0
+	proc   *processor
+	procMu sync.RWMutex
+)
+
+// Init 设置采样率及导出器
+func Init(opt *Option) {
+	rate = opt.SampleRate
+	switch opt.Exporter {
+	case ExporterStdout:
+		SetExporter(NewStdoutExporter(os.Stdout))
+	case ExporterOtlp:
+		SetExporter(NewOtlpExporter(opt.Endpoint, opt.Service))
+	}
+}
+
+// SetExporter 替换导出器并启动批量导出, 传入nil时停止导出
+func SetExporter(e Exporter) {
+	Shutdown()
+	if e == nil {
+		return
+	}
+	p := &processor{
+		exporter: e,
+		queue:    make(chan *Span, queueSize),
+		done:     make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.run()
+	procMu.Lock()
+	proc = p
+	procMu.Unlock()
+}
+
+// Shutdown 导出队列中剩余的span后停止
+func Shutdown() {
+	procMu.Lock()
+	p := proc
+	proc = nil
+	procMu.Unlock()
+	if p == nil {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+}
+
+func sampleRate() float64 {
+	return rate
+}
+
+func export(s *Span) {
+	procMu.RLock()
+	defer procMu.RUnlock()
+	if proc == nil {
+		return
+	}
+	select {
+	case proc.queue <- s:
+	default:
+		// 队列已满时丢弃, 避免阻塞业务
+	}
+}
+
+func (p *processor) run() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.exporter.Export(batch); utils.HasErr(err) {
+			log.Printf("[WARNING] export %d trace spans err: %+v\n", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case s := <-p.queue:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.done:
+			for {
+				select {
+				case s := <-p.queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{writer: w}
+}
+
+func (e *StdoutExporter) Export(spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	enc := json.NewEncoder(e.writer)
+	for _, s := range spans {
+		if err := enc.Encode(s); utils.HasErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewOtlpExporter(endpoint, service string) *OtlpExporter {
+	return &OtlpExporter{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *OtlpExporter) Export(spans []*Span) error {
+	var otlpSpans []interface{}
+	for _, s := range spans {
+		otlpSpans = append(otlpSpans, otlpSpan(s))
+	}
+	body := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": e.service}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": otlpScopeName},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(body)
+	if utils.HasErr(err) {
+		return err
+	}
+	response, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(b))
+	if utils.HasErr(err) {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("otlp export error with status %d: %s", response.StatusCode, msg)
+	}
+	return nil
+}
+
+func otlpSpan(s *Span) map[string]interface{} {
+	kinds := map[string]int{KindInternal: 1, KindServer: 2, KindClient: 3}
+	m := map[string]interface{}{
+		"traceId":           s.TraceId,
+		"spanId":            s.SpanId,
+		"name":              s.Name,
+		"kind":              kinds[s.Kind],
+		"startTimeUnixNano": strconv.FormatInt(s.StartTime.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+		"attributes":        otlpAttributes(s.Attributes),
+	}
+	if !utils.IsEmpty(s.ParentSpanId) {
+		m["parentSpanId"] = s.ParentSpanId
+	}
+	if !utils.IsEmpty(s.Error) {
+		m["status"] = map[string]interface{}{"code": 2, "message": s.Error}
+	}
+	return m
+}
+
+func otlpAttributes(attrs map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var list []interface{}
+	for _, k := range keys {
+		var value map[string]interface{}
+		switch v := attrs[k].(type) {
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		list = append(list, map[string]interface{}{"key": k, "value": value})
+	}
+	return list
+}
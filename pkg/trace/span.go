@@ -0,0 +1,95 @@
+package trace
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+const (
+	KindInternal = "internal"
+	KindServer   = "server"
+	KindClient   = "client"
+)
+
+type Span struct {
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	TraceId      string                 `json:"trace_id"`
+	SpanId       string                 `json:"span_id"`
+	ParentSpanId string                 `json:"parent_span_id,omitempty"`
+	StartTime    time.Time              `json:"start_time"`
+	EndTime      time.Time              `json:"end_time"`
+	Attributes   map[string]interface{} `json:"attributes"`
+	Error        string                 `json:"error,omitempty"`
+	sampled      bool
+	mu           sync.Mutex
+	ended        bool
+}
+
+// StartSpan 以上下文中的链路为父级创建span, 没有父级时生成新的链路, 返回附带新span的上下文
+func StartSpan(ctx context.Context, name, kind string) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent := FromContext(ctx)
+	sc := &SpanContext{SpanId: NewSpanId()}
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		SpanId:     sc.SpanId,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+	}
+	if parent != nil {
+		sc.TraceId = parent.TraceId
+		sc.Sampled = parent.Sampled
+		sc.RequestId = parent.RequestId
+		span.ParentSpanId = parent.SpanId
+	} else {
+		sc.TraceId = NewTraceId()
+		sc.Sampled = shouldSample()
+		sc.RequestId = sc.TraceId
+	}
+	span.TraceId = sc.TraceId
+	span.sampled = sc.Sampled
+	return NewContext(ctx, sc), span
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *Span) SetError(err error) {
+	if !utils.HasErr(err) {
+		return
+	}
+	s.mu.Lock()
+	s.Error = err.Error()
+	s.mu.Unlock()
+}
+
+// End 结束span, 已采样且配置了导出器时异步导出, 重复调用无效
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	if s.sampled {
+		export(s)
+	}
+}
+
+func shouldSample() bool {
+	rate := sampleRate()
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
@@ -0,0 +1,162 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/EvisuXiao/andrews-common/utils"
+)
+
+// W3C Trace Context及请求id的传播头
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderRequestId   = "X-Request-Id"
+)
+
+// ContextKey 链路信息在上下文中的键, gin.Context可直接通过Set(ContextKey, *SpanContext)设置
+const ContextKey = "trace_span_context"
+
+const traceVersion = "00"
+
+type contextKey string
+
+type SpanContext struct {
+	TraceId   string
+	SpanId    string
+	Sampled   bool
+	RequestId string
+}
+
+// Carrier 传播头的读写, 如http.Header及grpc metadata
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+type HeaderCarrier http.Header
+
+type MetadataCarrier metadata.MD
+
+// TraceParent 按 version-traceid-spanid-flags 格式输出
+func (sc *SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", traceVersion, sc.TraceId, sc.SpanId, flags)
+}
+
+func ParseTraceParent(s string) (*SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return nil, fmt.Errorf("invalid traceparent: %s", s)
+	}
+	traceId, spanId, flags := strings.ToLower(parts[1]), strings.ToLower(parts[2]), parts[3]
+	if !isHex(traceId, 32) || !isHex(spanId, 16) || !isHex(flags, 2) {
+		return nil, fmt.Errorf("invalid traceparent: %s", s)
+	}
+	if strings.Trim(traceId, "0") == "" || strings.Trim(spanId, "0") == "" {
+		return nil, fmt.Errorf("invalid traceparent: %s", s)
+	}
+	b, _ := hex.DecodeString(flags)
+	return &SpanContext{TraceId: traceId, SpanId: spanId, Sampled: b[0]&1 == 1}, nil
+}
+
+func NewTraceId() string {
+	return randomHex(16)
+}
+
+func NewSpanId() string {
+	return randomHex(8)
+}
+
+// NewContext 将链路信息写入上下文, logging.Ctx会自动附带trace_id, span_id, request_id
+func NewContext(ctx context.Context, sc *SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey(ContextKey), sc)
+}
+
+func FromContext(ctx context.Context) *SpanContext {
+	if ctx == nil {
+		return nil
+	}
+	v := ctx.Value(contextKey(ContextKey))
+	if v == nil {
+		v = ctx.Value(ContextKey)
+	}
+	sc, _ := v.(*SpanContext)
+	return sc
+}
+
+// Extract 从传播头读取上游链路, 请求id缺失时使用trace id
+// 不存在或格式错误时按采样率生成新的链路, 此时SpanId为空, 以此启动的span为根span
+func Extract(carrier Carrier) *SpanContext {
+	sc, err := ParseTraceParent(carrier.Get(HeaderTraceParent))
+	if utils.HasErr(err) {
+		sc = &SpanContext{TraceId: NewTraceId(), Sampled: shouldSample()}
+	}
+	sc.RequestId = carrier.Get(HeaderRequestId)
+	if utils.IsEmpty(sc.RequestId) {
+		sc.RequestId = sc.TraceId
+	}
+	return sc
+}
+
+// Inject 将上下文中的链路写入传播头, 上下文没有链路时不做处理
+func Inject(ctx context.Context, carrier Carrier) {
+	sc := FromContext(ctx)
+	if sc == nil {
+		return
+	}
+	if !utils.IsEmpty(sc.SpanId) {
+		carrier.Set(HeaderTraceParent, sc.TraceParent())
+	}
+	if !utils.IsEmpty(sc.RequestId) {
+		carrier.Set(HeaderRequestId, sc.RequestId)
+	}
+}
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		_, _ = rand.Read(b)
+		for _, v := range b {
+			if v != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -2,6 +2,8 @@ package validator
 
 import (
 	"errors"
+	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/locales"
@@ -38,6 +40,17 @@ func Init() {
 	}
 }
 
+// fieldName 请求中的字段名称, 依次取json, form, uri, header标签
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri", "header"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if !utils.IsEmpty(name) && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 func GetValidator() *validator.Validate {
 	return validate
 }
@@ -55,16 +68,96 @@ func SwitchLocale(l string) error {
 }
 
 func Check(v interface{}) error {
-	return Translate(GetValidator().Struct(v))
+	return Translate(validateValue(v))
+}
+
+// CheckAll 返回全部校验错误, 键为字段路径
+func CheckAll(v interface{}) map[string]string {
+	return TranslateFields(validateValue(v))
+}
+
+// CheckRequest 同CheckAll, 键为请求中的字段路径, 如LoginReq.user.name
+func CheckRequest(v interface{}) map[string]string {
+	err := validateValue(v)
+	validationErr, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return TranslateFields(err)
+	}
+	t := reflect.TypeOf(v)
+	fields := make(map[string]string)
+	for _, vErr := range validationErr {
+		fields[requestNamespace(t, vErr.StructNamespace())] = vErr.Translate(GetTranslator())
+	}
+	return fields
+}
+
+// requestNamespace 将结构体字段路径中的字段名转为请求中的名称, 根结构体名称及下标保持不变
+func requestNamespace(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	for i, part := range parts {
+		name, index := part, ""
+		if j := strings.Index(part, "["); j >= 0 {
+			name, index = part[:j], part[j:]
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if i > 0 && !utils.IsEmpty(name) && t.Kind() == reflect.Struct {
+			field, ok := t.FieldByName(name)
+			if !ok {
+				return namespace
+			}
+			parts[i] = fieldName(field) + index
+			t = field.Type
+		}
+		for k := strings.Count(index, "["); k > 0; k-- {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Map && t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return namespace
+			}
+			t = t.Elem()
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// validateValue map及slice类型逐项校验
+func validateValue(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice {
+		return GetValidator().Var(rv.Interface(), "dive")
+	}
+	return GetValidator().Struct(v)
 }
 
 func Translate(err error) error {
 	if !utils.HasErr(err) {
 		return nil
 	}
-	validationErr := err.(validator.ValidationErrors)
+	validationErr, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
 	for _, vErr := range validationErr {
 		return errors.New(vErr.Translate(GetTranslator()))
 	}
 	return err
 }
+
+// TranslateFields 翻译全部校验错误, 非校验错误时键为空
+func TranslateFields(err error) map[string]string {
+	if !utils.HasErr(err) {
+		return nil
+	}
+	validationErr, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return map[string]string{"": err.Error()}
+	}
+	fields := make(map[string]string)
+	for _, vErr := range validationErr {
+		fields[vErr.Namespace()] = vErr.Translate(GetTranslator())
+	}
+	return fields
+}
@@ -1,7 +1,13 @@
 package server
 
 import (
+	"context"
+
 	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/health"
+	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/pkg/consul"
+	"github.com/EvisuXiao/andrews-common/pkg/etcd"
 	"github.com/EvisuXiao/andrews-common/pkg/nacos"
 )
 
@@ -10,17 +16,76 @@ type IDiscovery interface {
 	UnregisterInstance(int) error
 }
 
-var discoveryAdapter IDiscovery
+// DiscoveryAdapter 根据中心配置初始化服务发现客户端
+type DiscoveryAdapter func(*config.Center) IDiscovery
+
+// noopDiscovery 适配器不支持服务发现时使用
+type noopDiscovery struct{}
+
+var (
+	discoveryAdapter  IDiscovery
+	discoveryAdapters = make(map[string]DiscoveryAdapter)
+)
+
+func init() {
+	RegisterDiscoveryAdapter(config.CenterNacos, initNacos)
+	RegisterDiscoveryAdapter(config.CenterEtcd, initEtcd)
+	RegisterDiscoveryAdapter(config.CenterConsul, initConsul)
+}
+
+// RegisterDiscoveryAdapter 注册服务发现适配器, 与配置中心共用-center参数选择
+func RegisterDiscoveryAdapter(name string, adapter DiscoveryAdapter) {
+	discoveryAdapters[name] = adapter
+}
 
 func initDiscoveryAdapter() {
-	initNacos()
+	name := config.GetCenterName()
+	adapter, ok := discoveryAdapters[name]
+	if !ok {
+		logging.Warning("Discovery adapter %s is not supported, skip registering instance", name)
+		discoveryAdapter = &noopDiscovery{}
+		return
+	}
+	discoveryAdapter = adapter(config.LoadCenterConfig())
+	registerDiscoveryChecker(name)
+}
+
+// registerDiscoveryChecker 客户端支持Ping时计入就绪检查, health.discovery为false时跳过
+func registerDiscoveryChecker(name string) {
+	if !config.GetServerConfig().Health.Discovery {
+		return
+	}
+	if c, ok := discoveryAdapter.(interface{ Ping(context.Context) error }); ok {
+		health.RegisterChecker(name, c.Ping)
+	}
+}
+
+func initNacos(c *config.Center) IDiscovery {
+	config.CheckCenterConfig(c.Nacos)
+	nacos.InitNaming(c.Nacos)
+	return nacos.GetNamingClient()
+}
+
+func initEtcd(c *config.Center) IDiscovery {
+	config.CheckCenterConfig(c.Etcd)
+	etcd.InitNaming(c.Etcd)
+	return etcd.GetNamingClient()
 }
 
-func initNacos() {
-	nacos.InitNaming(config.GetCenterConfig().Nacos)
-	discoveryAdapter = nacos.GetNamingClient()
+func initConsul(c *config.Center) IDiscovery {
+	config.CheckCenterConfig(c.Consul)
+	consul.InitNaming(c.Consul)
+	return consul.GetNamingClient()
 }
 
 func GetDiscoverer() IDiscovery {
 	return discoveryAdapter
 }
+
+func (d *noopDiscovery) RegisterInstance(int, float64, map[string]string) error {
+	return nil
+}
+
+func (d *noopDiscovery) UnregisterInstance(int) error {
+	return nil
+}
@@ -5,10 +5,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	common "github.com/EvisuXiao/andrews-common"
 	"github.com/EvisuXiao/andrews-common/config"
+	"github.com/EvisuXiao/andrews-common/health"
 	"github.com/EvisuXiao/andrews-common/logging"
+	"github.com/EvisuXiao/andrews-common/metrics"
 	"github.com/EvisuXiao/andrews-common/utils"
 )
 
@@ -48,9 +51,17 @@ func (r *Runner) Run() {
 
 func (r *Runner) Stop() {
 	logging.Info("Server is quitting")
+	health.SetReady(false)
+	if drain := r.srv.Config().Timeout.Drain; drain > 0 {
+		logging.Info("Wait %s for draining traffic", drain)
+		time.Sleep(drain)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), r.srv.Config().Timeout.Exit)
 	defer cancel()
 	go r.onStop()
+	if err := metrics.Shutdown(ctx); utils.HasErr(err) {
+		logging.Error("Stop metrics server err: %+v", err)
+	}
 	if err := r.srv.Stop(); utils.HasErr(err) {
 		logging.Error("Stop server err: %+v", err)
 		close(r.process)
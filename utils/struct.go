@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -142,7 +144,19 @@ func MapStringToStruct(m map[string]string, data interface{}, tFormat ...string)
 	}
 }
 
+// SetStructDefaultValue 按default标签为零值字段设置默认值, 并递归处理结构体及map/slice中的元素
+// time.Duration支持 "60s" 格式, slice支持 "a,b" 或json数组, map支持 "k1:v1,k2:v2" 或json对象
 func SetStructDefaultValue(s interface{}) {
+	setStructDefault(s, &defaulter{})
+}
+
+// SetStructDefaultValueBySource 仅为来源中未给出的字段设置默认值, 显式给出的false, 0, 空列表等保持不变
+// src为来源解码得到的map[string]interface{}及[]interface{}嵌套结构, match判断字段是否对应来源中的键
+func SetStructDefaultValueBySource(s interface{}, src interface{}, match func(field reflect.StructField, key string) bool) {
+	setStructDefault(s, &defaulter{match: match, src: src})
+}
+
+func setStructDefault(s interface{}, d *defaulter) {
 	v := reflect.ValueOf(s)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
@@ -150,36 +164,176 @@ func SetStructDefaultValue(s interface{}) {
 	if !v.CanAddr() {
 		return
 	}
-	tp := v.Type()
-	if tp.Kind() != reflect.Struct {
-		return
+	d.set(v, "", d.src, true)
+}
+
+// defaulter match为空时按零值判断是否缺省, 否则按字段是否出现在来源中判断
+type defaulter struct {
+	match func(field reflect.StructField, key string) bool
+	src   interface{}
+}
+
+func (d *defaulter) absent(v reflect.Value, present bool) bool {
+	if d.match != nil {
+		return !present
 	}
-	num := tp.NumField()
-	for i := 0; i < num; i++ {
-		vField := v.Field(i)
-		if vField.Kind() == reflect.Ptr {
-			if vField.IsNil() {
-				vField.Set(reflect.New(vField.Type().Elem()))
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// field 结构体字段在来源中对应的值, 匿名字段与外层共用同一层
+func (d *defaulter) field(src interface{}, field reflect.StructField) (interface{}, bool) {
+	m, ok := src.(map[string]interface{})
+	if !ok || d.match == nil {
+		return nil, false
+	}
+	if field.Anonymous {
+		return src, true
+	}
+	for k, item := range m {
+		if d.match(field, k) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func (d *defaulter) set(v reflect.Value, defaultVal string, src interface{}, present bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if !v.CanSet() {
+				return
 			}
-			vField = vField.Elem()
+			v.Set(reflect.New(v.Type().Elem()))
 		}
-		if !vField.CanInterface() {
+		d.set(v.Elem(), defaultVal, src, present)
+	case reflect.Struct:
+		if _, ok := v.Interface().(time.Time); ok {
 			return
 		}
-		if vField.Kind() == reflect.Struct {
-			SetStructDefaultValue(vField.Addr().Interface())
-			continue
+		tp := v.Type()
+		for i := 0; i < tp.NumField(); i++ {
+			if vField := v.Field(i); vField.CanSet() {
+				item, ok := d.field(src, tp.Field(i))
+				d.set(vField, tp.Field(i).Tag.Get("default"), item, ok)
+			}
+		}
+	case reflect.Map:
+		if !IsEmpty(defaultVal) && v.CanSet() && d.absent(v, present) {
+			setMapValue(v, defaultVal)
+		}
+		if !isCompositeKind(v.Type().Elem()) {
+			return
+		}
+		m, _ := src.(map[string]interface{})
+		// map元素不可寻址, 指针元素直接处理, 其他元素复制后写回
+		for _, key := range v.MapKeys() {
+			elem := v.MapIndex(key)
+			item, ok := m[fmt.Sprint(key.Interface())]
+			if elem.Kind() == reflect.Ptr {
+				if !elem.IsNil() {
+					d.set(elem.Elem(), "", item, ok)
+				}
+				continue
+			}
+			copied := reflect.New(elem.Type()).Elem()
+			copied.Set(elem)
+			d.set(copied, "", item, ok)
+			v.SetMapIndex(key, copied)
+		}
+	case reflect.Slice:
+		if !IsEmpty(defaultVal) && v.CanSet() && d.absent(v, present) {
+			setSliceValue(v, defaultVal)
 		}
-		if vField.CanSet() {
-			defaultVal := tp.Field(i).Tag.Get("default")
-			if IsEmpty(defaultVal) {
+		if !isCompositeKind(v.Type().Elem()) {
+			return
+		}
+		l, _ := src.([]interface{})
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if item.Kind() == reflect.Ptr && item.IsNil() {
 				continue
 			}
-			if vField.IsZero() {
-				setSimpleFieldValue(vField, defaultVal)
+			var elemSrc interface{}
+			if i < len(l) {
+				elemSrc = l[i]
 			}
+			d.set(item, "", elemSrc, i < len(l))
+		}
+	default:
+		if !IsEmpty(defaultVal) && v.CanSet() && d.absent(v, present) {
+			setSimpleFieldValue(v, defaultVal)
+		}
+	}
+}
+
+// ParseDefaultValue 按default标签的规则将默认值解析为类型t的值, 与SetStructDefaultValue一致
+func ParseDefaultValue(t reflect.Type, val string) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Slice:
+		setSliceValue(v, val)
+	case reflect.Map:
+		setMapValue(v, val)
+	default:
+		setSimpleFieldValue(v, val)
+	}
+	return v
+}
+
+func isCompositeKind(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func setSliceValue(field reflect.Value, val string) {
+	if strings.HasPrefix(val, "[") {
+		ptr := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(val), ptr.Interface()); !HasErr(err) {
+			field.Set(ptr.Elem())
 		}
+		return
 	}
+	slice := reflect.MakeSlice(field.Type(), 0, 0)
+	for _, item := range strings.Split(val, ",") {
+		elem := reflect.New(field.Type().Elem()).Elem()
+		setSimpleFieldValue(elem, strings.TrimSpace(item))
+		slice = reflect.Append(slice, elem)
+	}
+	field.Set(slice)
+}
+
+func setMapValue(field reflect.Value, val string) {
+	if strings.HasPrefix(val, "{") {
+		ptr := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(val), ptr.Interface()); !HasErr(err) {
+			field.Set(ptr.Elem())
+		}
+		return
+	}
+	m := reflect.MakeMap(field.Type())
+	for _, item := range strings.Split(val, ",") {
+		kv := strings.SplitN(item, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := reflect.New(field.Type().Key()).Elem()
+		setSimpleFieldValue(key, strings.TrimSpace(kv[0]))
+		elem := reflect.New(field.Type().Elem()).Elem()
+		setSimpleFieldValue(elem, strings.TrimSpace(kv[1]))
+		m.SetMapIndex(key, elem)
+	}
+	field.Set(m)
 }
 
 func setSimpleFieldValue(field reflect.Value, val string) {
@@ -187,6 +341,12 @@ func setSimpleFieldValue(field reflect.Value, val string) {
 	case reflect.String:
 		field.SetString(val)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := field.Interface().(time.Duration); ok {
+			if d, err := time.ParseDuration(val); !HasErr(err) {
+				field.SetInt(int64(d))
+				return
+			}
+		}
 		strVal, _ := strconv.ParseInt(val, 10, 64)
 		field.SetInt(strVal)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
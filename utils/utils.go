@@ -32,6 +32,17 @@ func HasErr(err error) bool {
 	return err != nil
 }
 
+// NormalizeErr 将包装函数返回的nil指针错误(如nil的*exception.DbError)转为nil, 以便HasErr判断
+func NormalizeErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if value := reflect.ValueOf(err); value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil
+	}
+	return err
+}
+
 func IsSimpleValue(value interface{}) bool {
 	switch value.(type) {
 	case bool, string, byte, rune,